@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Adjustments                                 */
+/* -------------------------------------------------------------------------- */
+
+// GET lists the season's adjustments, POST records one.
+func (a *App) handleAdjustments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		adjs, err := a.adjustments.List()
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if adjs == nil {
+			adjs = []models.Adjustment{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(adjs)
+	case http.MethodPost:
+		var adj models.Adjustment
+		if err := json.NewDecoder(r.Body).Decode(&adj); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		adj.ID = 0
+		created, err := a.adjustments.As(actorOf(r)).Create(adj)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(created)
+	default:
+		http.Error(w, "only GET or POST allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET returns one adjustment, DELETE withdraws it.
+func (a *App) handleAdjustment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid adjustment id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		adj, err := a.adjustments.Get(id)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(adj)
+	case http.MethodDelete:
+		if err := a.adjustments.As(actorOf(r)).Delete(id); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "only GET or DELETE allowed", http.StatusMethodNotAllowed)
+	}
+}
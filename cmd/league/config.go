@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/musta/insider-league/internal/models"
+	"github.com/musta/insider-league/internal/service"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Generator config                              */
+/* -------------------------------------------------------------------------- */
+
+// seasonParam returns ?season=, or the current season when it is absent.
+func (a *App) seasonParam(r *http.Request) (int, error) {
+	if v := r.URL.Query().Get("season"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%w: season must be a positive integer", errBadRequest)
+		}
+		return n, nil
+	}
+	return a.repo.CurrentSeason()
+}
+
+// seasonConfig loads the GeneratorConfig of the requested season.
+func (a *App) seasonConfig(r *http.Request) (models.GeneratorConfig, error) {
+	season, err := a.seasonParam(r)
+	if err != nil {
+		return models.GeneratorConfig{}, err
+	}
+	return a.repo.GetGeneratorConfig(season)
+}
+
+// GET returns the season's config, PUT replaces it.
+func (a *App) handleGeneratorConfig(w http.ResponseWriter, r *http.Request) {
+	season, err := a.seasonParam(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		cfg := models.DefaultGeneratorConfig()
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		cfg.Season = season
+		if err := cfg.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.repo.SaveGeneratorConfig(cfg); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "only GET or PUT allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, err := a.repo.GetGeneratorConfig(season)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(cfg)
+}
+
+// Fits the current season's config to its results and saves it. The
+// results are always the current season's, so ?season= is refused.
+func (a *App) handleCalibrateGenerator(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Has("season") {
+		http.Error(w, "calibrate always fits the current season; season is not supported", http.StatusBadRequest)
+		return
+	}
+	base, err := a.seasonConfig(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	matches, err := a.repo.GetAllMatches()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cfg, err := service.CalibrateGeneratorConfig(base, matches)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := a.repo.SaveGeneratorConfig(cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"matches": len(matches),
+		"config":  cfg,
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/musta/insider-league/internal/cup"
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                    Cup                                     */
+/* -------------------------------------------------------------------------- */
+
+// cupRequest is the body shared by the /cup endpoints. Every field is
+// optional: without team_ids all league teams enter, without a bracket a
+// new one is drawn.
+type cupRequest struct {
+	cup.Config
+	TeamIDs []int        `json:"team_ids"`
+	Seeded  bool         `json:"seeded"`
+	Bracket *cup.Bracket `json:"bracket"`
+}
+
+// readCupRequest decodes the body and loads the entrants.
+func (a *App) readCupRequest(r *http.Request) (cupRequest, []models.Team, error) {
+	req := cupRequest{Config: cup.DefaultConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return req, nil, fmt.Errorf("%w: invalid JSON", errBadRequest)
+	}
+	if err := req.Config.Validate(); err != nil {
+		return req, nil, err
+	}
+	teams, err := a.entrants(req.TeamIDs)
+	return req, teams, err
+}
+
+// entrants returns the league teams with the given ids, or every team
+// when ids is empty.
+func (a *App) entrants(ids []int) ([]models.Team, error) {
+	teams, err := a.repo.ListTeams()
+	if err != nil || len(ids) == 0 {
+		return teams, err
+	}
+	byID := make(map[int]models.Team, len(teams))
+	for _, t := range teams {
+		byID[t.ID] = t
+	}
+	out := make([]models.Team, 0, len(ids))
+	for _, id := range ids {
+		t, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown team %d", errBadRequest, id)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// bracketFor returns the request's bracket, or draws one.
+func bracketFor(req cupRequest, teams []models.Team, rng *rand.Rand) (cup.Bracket, error) {
+	if req.Bracket != nil {
+		return *req.Bracket, req.Bracket.Validate(teams)
+	}
+	return cup.Draw(teams, req.Seeded, rng)
+}
+
+// Draws a bracket from the league's teams
+func (a *App) handleCupDraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req, teams, err := a.readCupRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	req.Bracket = nil
+	b, err := bracketFor(req, teams, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"bracket": b,
+		"rounds":  cup.RoundNames(len(b.Slots)),
+	})
+}
+
+// Plays a cup to the end with ?generator=
+func (a *App) handleCupSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, teams, err := a.readCupRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	b, err := bracketFor(req, teams, rng)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	res, err := cup.Simulate(b, teams, gen, req.Config, rng)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"bracket": b,
+		"result":  res,
+	})
+}
+
+// Monte Carlo odds of reaching each round; without a bracket every run
+// makes its own draw
+func (a *App) handleCupPredict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sims := 5000
+	if q := r.URL.Query().Get("sims"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n > 0 {
+			sims = n
+		}
+	}
+	newGen, err := a.generatorFactoryFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, teams, err := a.readCupRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if req.Bracket != nil {
+		if err := req.Bracket.Validate(teams); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	odds, err := cup.Predict(req.Bracket, req.Seeded, teams, newGen, req.Config, sims, rng)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	size := len(teams)
+	if req.Bracket != nil {
+		size = len(req.Bracket.Slots)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sims":   sims,
+		"rounds": cup.RoundNames(size),
+		"odds":   odds,
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Dates                                    */
+/* -------------------------------------------------------------------------- */
+
+// GET returns the season's dates, PUT sets them and DELETE removes them.
+func (a *App) handleDates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		d, err := a.seasonDates.Get()
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d)
+	case http.MethodPut:
+		var d models.SeasonDates
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		stored, err := a.seasonDates.Set(d)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(stored)
+	case http.MethodDelete:
+		if err := a.seasonDates.Clear(); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "only GET, PUT or DELETE allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Exports a team's dated fixtures as an iCalendar feed
+func (a *App) handleTeamCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid team id", http.StatusBadRequest)
+		return
+	}
+	ics, err := a.seasonDates.TeamCalendar(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="team-%d.ics"`, id))
+	_, _ = w.Write(ics)
+}
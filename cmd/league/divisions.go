@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Divisions                                  */
+/* -------------------------------------------------------------------------- */
+
+// GET returns the pyramid, PUT replaces it.
+func (a *App) handleDivisions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		divs, err := a.pyramid.Get()
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(divs)
+	case http.MethodPut:
+		var divs []models.Division
+		if err := json.NewDecoder(r.Body).Decode(&divs); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		stored, err := a.pyramid.Replace(divs)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(stored)
+	default:
+		http.Error(w, "only GET or PUT allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Plays one season of every division with ?generator=, storing nothing
+func (a *App) handleDivisionsSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, err := a.pyramid.Simulate(gen, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// Ends the stored season: plays the playoffs with ?generator=, promotes,
+// relegates, carries ratings over and starts the next season (?name=)
+func (a *App) handleDivisionsRollover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, next, err := a.pyramid.Rollover(r.URL.Query().Get("name"), gen, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"season":    res,
+		"divisions": next,
+	})
+}
+
+// Monte Carlo title, promotion, playoff and relegation odds
+func (a *App) handleDivisionsPredict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sims := 1000
+	if q := r.URL.Query().Get("sims"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n > 0 {
+			sims = n
+		}
+	}
+	newGen, err := a.generatorFactoryFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	odds, err := a.pyramid.Predict(newGen, sims, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sims": sims,
+		"odds": odds,
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Head-to-head                                */
+/* -------------------------------------------------------------------------- */
+
+// Returns every meeting of ?team1= and ?team2= and their record
+func (a *App) handleHeadToHead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	team1, err1 := strconv.Atoi(r.URL.Query().Get("team1"))
+	team2, err2 := strconv.Atoi(r.URL.Query().Get("team2"))
+	if err1 != nil || err2 != nil {
+		http.Error(w, "team1 and team2 must be team ids", http.StatusBadRequest)
+		return
+	}
+	h, err := a.history.HeadToHead(team1, team2)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h)
+}
+
+// Returns the current season's results grid
+func (a *App) handleResultsGrid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	g, err := a.history.Grid()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(g)
+}
+
+// GET lists imported results (?team= filters by name), POST imports more.
+func (a *App) handleHistory(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ms, err := a.history.List(r.URL.Query().Get("team"))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if ms == nil {
+			ms = []models.HistoricalMatch{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ms)
+	case http.MethodPost:
+		var ms []models.HistoricalMatch
+		if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		a.writeImported(w, ms)
+	default:
+		http.Error(w, "only GET or POST allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Imports the finished matches of a real league season from football-data.org
+func (a *App) handleRealHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	league := r.URL.Query().Get("league")
+	if league == "" {
+		http.Error(w, "league param required", http.StatusBadRequest)
+		return
+	}
+	season := r.URL.Query().Get("season")
+	apiKey := footballDataKey()
+	q := url.Values{"status": {"FINISHED"}}
+	if season != "" {
+		q.Set("season", season)
+	}
+	matchesUrl := fmt.Sprintf("https://api.football-data.org/v4/competitions/%s/matches?%s", url.PathEscape(league), q.Encode())
+	req, _ := http.NewRequest("GET", matchesUrl, nil)
+	req.Header.Set("X-Auth-Token", apiKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		http.Error(w, "football-data.org: "+resp.Status, http.StatusBadGateway)
+		return
+	}
+	type team struct {
+		Name      string `json:"name"`
+		ShortName string `json:"shortName"`
+	}
+	var matchesResp struct {
+		Matches []struct {
+			UtcDate  time.Time `json:"utcDate"`
+			HomeTeam team      `json:"homeTeam"`
+			AwayTeam team      `json:"awayTeam"`
+			Season   struct {
+				StartDate string `json:"startDate"`
+			} `json:"season"`
+			Score struct {
+				FullTime struct {
+					Home int `json:"home"`
+					Away int `json:"away"`
+				} `json:"fullTime"`
+			} `json:"score"`
+		} `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&matchesResp); err != nil {
+		http.Error(w, "football-data.org: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	// Teams are stored by short name, the one most likely to match ours.
+	name := func(t team) string {
+		if t.ShortName != "" {
+			return t.ShortName
+		}
+		return t.Name
+	}
+	ms := make([]models.HistoricalMatch, 0, len(matchesResp.Matches))
+	for _, m := range matchesResp.Matches {
+		label := season
+		if label == "" && len(m.Season.StartDate) >= 4 {
+			label = m.Season.StartDate[:4]
+		}
+		ms = append(ms, models.HistoricalMatch{
+			Source:      "football-data",
+			Competition: league,
+			Season:      label,
+			PlayedOn:    m.UtcDate,
+			HomeTeam:    name(m.HomeTeam),
+			AwayTeam:    name(m.AwayTeam),
+			HomeGoals:   m.Score.FullTime.Home,
+			AwayGoals:   m.Score.FullTime.Away,
+		})
+	}
+	a.writeImported(w, ms)
+}
+
+// writeImported imports ms and reports how many were new.
+func (a *App) writeImported(w http.ResponseWriter, ms []models.HistoricalMatch) {
+	added, err := a.history.Import(ms)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]int{
+		"imported": added,
+		"skipped":  len(ms) - added,
+	})
+}
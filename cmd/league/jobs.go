@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/musta/insider-league/internal/models"
+	"github.com/musta/insider-league/internal/service"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                    Jobs                                    */
+/* -------------------------------------------------------------------------- */
+
+// Defaults of the job params.
+const (
+	defaultJobSims      = 5000
+	defaultBacktestSims = 1000
+	defaultBatchSeasons = 1000
+)
+
+// registerJobs gives the job service a runner for every job kind.
+func (a *App) registerJobs() {
+	a.jobs.Register(models.JobPredict, predictJob{a})
+	a.jobs.Register(models.JobBacktest, backtestJob{a})
+	a.jobs.Register(models.JobSeasonBatch, seasonBatchJob{a})
+}
+
+// decodeParams decodes job params into v, rejecting unknown fields so a
+// misspelt param is not silently ignored.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("params: %v", err)
+	}
+	return nil
+}
+
+// jobConfig loads the current season's GeneratorConfig. Jobs always work
+// on the current season, as it is when they start.
+func (a *App) jobConfig() (models.GeneratorConfig, error) {
+	season, err := a.repo.CurrentSeason()
+	if err != nil {
+		return models.GeneratorConfig{}, err
+	}
+	return a.repo.GetGeneratorConfig(season)
+}
+
+// checkGenerator rejects generator names /simulate would reject.
+func checkGenerator(name string) error {
+	_, err := generatorNamed(name, models.DefaultGeneratorConfig())
+	return err
+}
+
+/* ------------ Predict ----------------------------------------------------- */
+
+// predictJob is /predict run in the background.
+type predictJob struct{ a *App }
+
+type predictParams struct {
+	Model string `json:"model"`
+	Sims  int    `json:"sims"`
+}
+
+func (p *predictParams) decode(params json.RawMessage) error {
+	if err := decodeParams(params, p); err != nil {
+		return err
+	}
+	if p.Sims == 0 {
+		p.Sims = defaultJobSims
+	}
+	switch {
+	case p.Sims < 0:
+		return errors.New("sims must be positive")
+	case p.Model != "" && !slices.Contains(predictModels, p.Model):
+		return fmt.Errorf("unknown model %q", p.Model)
+	}
+	return nil
+}
+
+func (j predictJob) Check(params json.RawMessage) error {
+	var p predictParams
+	return p.decode(params)
+}
+
+func (j predictJob) Run(ctx context.Context, params json.RawMessage, progress func(float64)) (interface{}, error) {
+	var p predictParams
+	if err := p.decode(params); err != nil {
+		return nil, err
+	}
+	cfg, err := j.a.jobConfig()
+	if err != nil {
+		return nil, err
+	}
+	pr, err := j.a.newPrediction(p.Model, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := pr.run(p.Sims, streamBatch(p.Sims), func(res predictResult) error {
+		progress(float64(res.Sims) / float64(p.Sims))
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+/* ------------ Backtest ---------------------------------------------------- */
+
+// backtestJob scores generators' forecasts of the current season's played
+// matches.
+type backtestJob struct{ a *App }
+
+type backtestParams struct {
+	Generators []string `json:"generators"`
+	Sims       int      `json:"sims"` // scores generated per match
+	FromWeek   int      `json:"from_week"`
+}
+
+func (p *backtestParams) decode(params json.RawMessage) error {
+	if err := decodeParams(params, p); err != nil {
+		return err
+	}
+	if len(p.Generators) == 0 {
+		p.Generators = []string{"poisson"}
+	}
+	if p.Sims == 0 {
+		p.Sims = defaultBacktestSims
+	}
+	switch {
+	case p.Sims < 0:
+		return errors.New("sims must be positive")
+	case p.FromWeek < 0:
+		return errors.New("from_week must be ≥ 0")
+	}
+	for _, name := range p.Generators {
+		if err := checkGenerator(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j backtestJob) Check(params json.RawMessage) error {
+	var p backtestParams
+	return p.decode(params)
+}
+
+func (j backtestJob) Run(ctx context.Context, params json.RawMessage, progress func(float64)) (interface{}, error) {
+	var p backtestParams
+	if err := p.decode(params); err != nil {
+		return nil, err
+	}
+	cfg, err := j.a.jobConfig()
+	if err != nil {
+		return nil, err
+	}
+	teams, err := j.a.repo.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+	all, err := j.a.repo.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+	var played []models.MatchResult
+	for _, m := range all {
+		if m.Week >= p.FromWeek {
+			played = append(played, m)
+		}
+	}
+	if len(played) == 0 {
+		return nil, fmt.Errorf("no played matches from week %d", p.FromWeek)
+	}
+
+	total := float64(len(p.Generators) * len(played))
+	out := make([]models.Backtest, len(p.Generators))
+	for i, name := range p.Generators {
+		newGen, err := generatorFactory(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		score, weeks, err := service.Backtest(newGen, teams, played, p.Sims, func(done int) error {
+			progress(float64(i*len(played)+done) / total)
+			return ctx.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+		out[i] = models.Backtest{Generator: name, BacktestScore: score, Weeks: weeks}
+	}
+	return map[string]interface{}{"sims": p.Sims, "backtests": out}, nil
+}
+
+/* ------------ Season batch ------------------------------------------------ */
+
+// seasonBatchJob plays the rest of the current season many times over.
+type seasonBatchJob struct{ a *App }
+
+type seasonBatchParams struct {
+	Generator string `json:"generator"`
+	Seasons   int    `json:"seasons"`
+}
+
+func (p *seasonBatchParams) decode(params json.RawMessage) error {
+	if err := decodeParams(params, p); err != nil {
+		return err
+	}
+	if p.Seasons == 0 {
+		p.Seasons = defaultBatchSeasons
+	}
+	if p.Seasons < 0 {
+		return errors.New("seasons must be positive")
+	}
+	return checkGenerator(p.Generator)
+}
+
+func (j seasonBatchJob) Check(params json.RawMessage) error {
+	var p seasonBatchParams
+	return p.decode(params)
+}
+
+func (j seasonBatchJob) Run(ctx context.Context, params json.RawMessage, progress func(float64)) (interface{}, error) {
+	var p seasonBatchParams
+	if err := p.decode(params); err != nil {
+		return nil, err
+	}
+	splitCfg, err := j.a.splits.Get()
+	if err != nil {
+		return nil, err
+	}
+	if splitCfg.Enabled() {
+		return nil, errors.New("season batches cannot play out a split season")
+	}
+	cfg, err := j.a.jobConfig()
+	if err != nil {
+		return nil, err
+	}
+	newGen, err := generatorFactory(p.Generator, cfg)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := j.a.repo.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+	table, err := j.a.repo.GetTable()
+	if err != nil {
+		return nil, err
+	}
+	remain, err := j.a.repo.ListRemainingMatches()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := j.a.adjustments.Pending()
+	if err != nil {
+		return nil, err
+	}
+	u := service.NewTableUpdater()
+	if table, err = service.ApplyAdjustments(u, table, pending); err != nil {
+		return nil, err
+	}
+
+	finish, err := service.PlaySeasons(newGen, u, teams, table, remain, p.Seasons, func(done int) error {
+		progress(float64(done) / float64(p.Seasons))
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	name := p.Generator
+	if name == "" {
+		name = "poisson"
+	}
+	return models.SeasonBatch{Generator: name, Seasons: p.Seasons, Teams: finish}, nil
+}
+
+/* ------------ Handlers ---------------------------------------------------- */
+
+// GET lists jobs (?status= filters), POST {"kind": "...", "params": {...}}
+// queues one
+func (a *App) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		jobs, err := a.jobs.List(r.URL.Query().Get("status"))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(jobs)
+	case http.MethodPost:
+		var req struct {
+			Kind   string          `json:"kind"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		j, err := a.jobs.As(actorOf(r)).Submit(req.Kind, req.Params)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", fmt.Sprintf("/jobs/%d", j.ID))
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(j)
+	default:
+		http.Error(w, "only GET or POST allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Returns one job with its progress and, once it has succeeded, its result
+func (a *App) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	j, err := a.jobs.Get(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(j)
+}
+
+// Cancels a queued or running job
+func (a *App) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	j, err := a.jobs.Cancel(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(j)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/musta/insider-league/internal/engine"
+	"github.com/musta/insider-league/internal/models"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Live matchday                               */
+/* -------------------------------------------------------------------------- */
+
+// Pace of a live matchday, in milliseconds per match minute.
+const (
+	defaultLivePace = 200
+	maxLivePace     = 5000
+)
+
+// liveFixture is a fixture as announced at kickoff, before its score.
+type liveFixture struct {
+	Week       int       `json:"week"`
+	HomeTeamID int       `json:"home_team_id"`
+	AwayTeamID int       `json:"away_team_id"`
+	Kickoff    time.Time `json:"kickoff,omitzero"`
+}
+
+// liveEvent is a timeline event of one fixture.
+type liveEvent struct {
+	Week       int `json:"week"`
+	HomeTeamID int `json:"home_team_id"`
+	AwayTeamID int `json:"away_team_id"`
+	models.TimelineEvent
+}
+
+// liveClock is the time on the matchday's clock.
+type liveClock struct {
+	Minute    int `json:"minute"`
+	AddedTime int `json:"added_time,omitempty"`
+}
+
+// livePace returns ?pace=, or defaultLivePace when it is missing.
+func livePace(r *http.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("pace")
+	if v == "" {
+		return defaultLivePace * time.Millisecond, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > maxLivePace {
+		return 0, fmt.Errorf("pace must be an integer between 0 and %d", maxLivePace)
+	}
+	return time.Duration(n) * time.Millisecond, nil
+}
+
+// Plays the next matchday with the minute-by-minute engine and streams it
+// to the client as it unfolds
+func (a *App) handleSimulateWeekLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	pace, err := livePace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg, err := a.seasonConfig(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	// The matchday is saved before it is streamed, so a client that leaves
+	// early does not lose it.
+	wr, err := a.sim.As(actorOf(r)).SimulateWeek(engine.New(cfg))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	table, err := a.repo.GetTable()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stream, err := newSSEStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	kickoff := make([]liveFixture, len(wr.Fixtures))
+	for i, m := range wr.Fixtures {
+		kickoff[i] = liveFixture{Week: m.Week, HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID, Kickoff: m.Kickoff}
+	}
+	if stream.send("kickoff", kickoff) != nil {
+		return
+	}
+	for _, tick := range liveTicks(wr.Timelines) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(pace):
+		}
+		if stream.send("clock", tick.clock) != nil {
+			return
+		}
+		for _, ev := range tick.events {
+			ev.Week = wr.Week
+			if stream.send(ev.Kind, ev) != nil {
+				return
+			}
+		}
+	}
+	if stream.send("result", wr) != nil {
+		return
+	}
+	_ = stream.send("table", table)
+}
+
+// liveTick is one minute of a matchday and what happened in it.
+type liveTick struct {
+	clock  liveClock
+	events []liveEvent
+}
+
+// liveTicks lays the timelines of a matchday out minute by minute. The
+// clock runs through the longest stoppage time of each half.
+func liveTicks(timelines []models.MatchTimeline) []liveTick {
+	added := [2]int{}
+	for _, tl := range timelines {
+		for _, ev := range tl.Events {
+			switch ev.Kind {
+			case models.TimelineHalfTime:
+				added[0] = max(added[0], ev.AddedTime)
+			case models.TimelineFullTime:
+				added[1] = max(added[1], ev.AddedTime)
+			}
+		}
+	}
+
+	var ticks []liveTick
+	index := make(map[liveClock]int)
+	for half, end := range [2]int{45, 90} {
+		for m := end - 44; m <= end+added[half]; m++ {
+			c := liveClock{Minute: m}
+			if m > end {
+				c = liveClock{Minute: end, AddedTime: m - end}
+			}
+			index[c] = len(ticks)
+			ticks = append(ticks, liveTick{clock: c})
+		}
+	}
+	for _, tl := range timelines {
+		for _, ev := range tl.Events {
+			i := index[liveClock{Minute: ev.Minute, AddedTime: ev.AddedTime}]
+			ticks[i].events = append(ticks[i].events, liveEvent{
+				HomeTeamID: tl.HomeTeamID, AwayTeamID: tl.AwayTeamID, TimelineEvent: ev,
+			})
+		}
+	}
+	return ticks
+}
@@ -1,20 +1,32 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/musta/insider-league/internal/cup"
+	"github.com/musta/insider-league/internal/dates"
 	"github.com/musta/insider-league/internal/interfaces"
 	"github.com/musta/insider-league/internal/models"
 	"github.com/musta/insider-league/internal/predictor"
+	"github.com/musta/insider-league/internal/pyramid"
 	"github.com/musta/insider-league/internal/repo"
+	"github.com/musta/insider-league/internal/schedule"
 	"github.com/musta/insider-league/internal/service"
+	"github.com/musta/insider-league/internal/split"
+	"github.com/musta/insider-league/internal/tournament"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -22,25 +34,59 @@ import (
 /* -------------------------------------------------------------------------- */
 
 type App struct {
-	repo interfaces.Repository
-	gen  interfaces.MatchGenerator
+	repo        interfaces.Repository
+	sim         *service.SimulationService
+	snapshots   *service.SnapshotService
+	matches     *service.MatchService
+	teams       *service.TeamService
+	schedules   *service.ScheduleService
+	pyramid     *service.PyramidService
+	splits      *service.SplitService
+	adjustments *service.AdjustmentService
+	seasonDates *service.DatesService
+	stats       *service.StatsService
+	tables      *service.TableService
+	history     *service.HistoryService
+	jobs        *service.JobService
 }
 
-// nextFixtures returns *all* fixtures that belong to the next un-played week.
-func (a *App) nextFixtures() ([]interfaces.Matchup, error) {
-	remain, err := a.repo.ListRemainingMatches()
-	if err != nil || len(remain) == 0 {
+// generatorFor returns the MatchGenerator picked by ?generator=, or the
+// Poisson generator when the parameter is absent. Either way it uses the
+// current season's GeneratorConfig.
+func (a *App) generatorFor(r *http.Request) (interfaces.MatchGenerator, error) {
+	cfg, err := a.seasonConfig(r)
+	if err != nil {
 		return nil, err
 	}
-	nextWeek := remain[0].Week
-	var out []interfaces.Matchup
-	for _, f := range remain {
-		if f.Week != nextWeek {
-			break
-		}
-		out = append(out, f)
+	return generatorNamed(r.URL.Query().Get("generator"), cfg)
+}
+
+// generatorFactoryFor is generatorFor for Monte Carlo runs, which need a
+// fresh generator for every simulation.
+func (a *App) generatorFactoryFor(r *http.Request) (interfaces.GeneratorFactory, error) {
+	cfg, err := a.seasonConfig(r)
+	if err != nil {
+		return nil, err
 	}
-	return out, nil
+	return generatorFactory(r.URL.Query().Get("generator"), cfg)
+}
+
+// generatorFactory checks the generator name and returns a factory of the
+// named MatchGenerator.
+func generatorFactory(name string, cfg models.GeneratorConfig) (interfaces.GeneratorFactory, error) {
+	if _, err := generatorNamed(name, cfg); err != nil {
+		return nil, err
+	}
+	return func() (interfaces.MatchGenerator, error) { return generatorNamed(name, cfg) }, nil
+}
+
+// generatorNamed returns the named MatchGenerator, or the Poisson
+// generator when name is empty.
+func generatorNamed(name string, cfg models.GeneratorConfig) (interfaces.MatchGenerator, error) {
+	if name == "" {
+		return service.NewConfiguredPoissonGenerator(cfg), nil
+	}
+	return predictor.NewGenerator(name, cfg)
 }
 
 /* -------------------------------------------------------------------------- */
@@ -52,15 +98,118 @@ func (a *App) handleGetTable(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	table, err := a.repo.GetTable()
+	q := r.URL.Query()
+	filtered := q.Has("venue") || q.Has("from") || q.Has("to") || q.Has("teams")
+	var table models.LeagueTable
+	var err error
+	switch {
+	case q.Has("at") && q.Has("week"), q.Has("at") && filtered, q.Has("week") && filtered:
+		err = fmt.Errorf("%w: at, week and the venue/from/to/teams filters cannot be combined", errBadRequest)
+	case q.Has("at"):
+		table, err = a.tableAt(q.Get("at"))
+	case q.Has("week"):
+		week, convErr := strconv.Atoi(q.Get("week"))
+		if convErr != nil {
+			err = fmt.Errorf("%w: week must be a number", errBadRequest)
+			break
+		}
+		table, err = a.tables.After(week)
+	case filtered:
+		var tq service.TableQuery
+		if tq, err = tableQuery(q); err == nil {
+			table, err = a.tables.Filtered(tq)
+		}
+	default:
+		table, err = a.repo.GetTable()
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(table)
 }
 
+// tableQuery reads the venue, from, to and teams filters of /table.
+func tableQuery(q url.Values) (service.TableQuery, error) {
+	tq := service.TableQuery{Venue: q.Get("venue")}
+	for _, p := range []struct {
+		name string
+		week *int
+	}{{"from", &tq.FromWeek}, {"to", &tq.ToWeek}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return tq, fmt.Errorf("%w: %s must be a week number", errBadRequest, p.name)
+			}
+			*p.week = n
+		}
+	}
+	if v := q.Get("teams"); v != "" {
+		for _, f := range strings.Split(v, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return tq, fmt.Errorf("%w: teams must be comma-separated team ids", errBadRequest)
+			}
+			tq.Teams = append(tq.Teams, id)
+		}
+	}
+	return tq, nil
+}
+
+// tableAt rebuilds the table as it stood at an RFC 3339 timestamp.
+func (a *App) tableAt(at string) (models.LeagueTable, error) {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return nil, fmt.Errorf("%w: at must be an RFC 3339 timestamp", errBadRequest)
+	}
+	matches, err := a.repo.GetMatchesAt(t)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := a.repo.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+	lastWeek := 0
+	for _, m := range matches {
+		if m.Week > lastWeek {
+			lastWeek = m.Week
+		}
+	}
+	// The split only shows once group fixtures had been played by then.
+	splitCfg, err := a.repo.GetSplit()
+	if err != nil {
+		return nil, err
+	}
+	if lastWeek <= splitCfg.AfterWeek {
+		splitCfg = models.SplitConfig{}
+	}
+	// Adjustments count once their week had been played and they had
+	// been recorded.
+	all, err := a.repo.ListAdjustments()
+	if err != nil {
+		return nil, err
+	}
+	var adjs []models.Adjustment
+	for _, adj := range all {
+		if adj.Week <= lastWeek && !adj.CreatedAt.After(t) {
+			adjs = append(adjs, adj)
+		}
+	}
+	table, err := split.Table(service.NewTableUpdater(), teams, matches, adjs, splitCfg)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := a.repo.ListFixtures()
+	if err != nil {
+		return nil, err
+	}
+	service.CountByes(table, fixtures, lastWeek)
+	service.CountPostponed(table, fixtures, lastWeek)
+	return table, nil
+}
+
 /* ------------ 1 week ------------------------------------------------------ */
 
 func (a *App) handleSimulateWeek(w http.ResponseWriter, r *http.Request) {
@@ -68,22 +217,21 @@ func (a *App) handleSimulateWeek(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	fixtures, err := a.nextFixtures()
+	gen, err := a.generatorFor(r)
 	if err != nil {
-		http.Error(w, "no remaining fixtures", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	teams, _ := a.repo.ListTeams()
-	for _, f := range fixtures {
-		home := findTeam(teams, f.HomeTeamID)
-		away := findTeam(teams, f.AwayTeamID)
-		match, _ := a.gen.Generate(home, away)
-		match.Week = f.Week
-		_ = a.repo.SaveMatch(match)
+	if _, err := a.sim.As(actorOf(r)).SimulateWeek(gen); err != nil {
+		writeServiceError(w, err)
+		return
 	}
 
-	table, _ := a.repo.GetTable()
+	table, err := a.repo.GetTable()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(table)
 }
@@ -95,6 +243,11 @@ func (a *App) handleSimulateWeeks(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	weeks := 1
 	if v := r.URL.Query().Get("weeks"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
@@ -102,37 +255,16 @@ func (a *App) handleSimulateWeeks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	type WeekResult struct {
-		Week     int                  `json:"week"`
-		Fixtures []models.MatchResult `json:"fixtures"`
+	if wantsStream(r) {
+		a.streamSimulated(w, r, gen, weeks)
+		return
 	}
-	var simulated []WeekResult
-
-	for i := 0; i < weeks; i++ {
-		fixtures, _ := a.nextFixtures()
-		if len(fixtures) == 0 {
-			break // season finished
-		}
-
-		teams, _ := a.repo.ListTeams()
-		var played []models.MatchResult
-		for _, f := range fixtures {
-			home := findTeam(teams, f.HomeTeamID)
-			away := findTeam(teams, f.AwayTeamID)
-			m, _ := a.gen.Generate(home, away)
-			m.Week = f.Week
-			_ = a.repo.SaveMatch(m)
-			played = append(played, m)
-		}
-		simulated = append(simulated, WeekResult{Week: fixtures[0].Week, Fixtures: played})
+	simulated, err := a.sim.As(actorOf(r)).SimulateWeeks(gen, weeks)
+	if err != nil {
+		a.writeSimulatedError(w, simulated, err)
+		return
 	}
-
-	table, _ := a.repo.GetTable()
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"simulated": simulated,
-		"table":     table,
-	})
+	a.writeSimulated(w, simulated)
 }
 
 /* ------------ Predictions ------------------------------------------------- */
@@ -142,9 +274,6 @@ func (a *App) handlePredict(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	teams, _ := a.repo.ListTeams()
-	table, _ := a.repo.GetTable()
-	remain, _ := a.repo.ListRemainingMatches()
 
 	// query params
 	modelName := r.URL.Query().Get("model")
@@ -155,35 +284,28 @@ func (a *App) handlePredict(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var model predictor.Predictor
-	switch modelName {
-	case "elo":
-		model = predictor.NewEloMonteCarlo()
-	case "bt":
-		model = predictor.NewBradleyTerryMonteCarlo()
-	case "logistic":
-		model = predictor.NewLogisticMonteCarlo()
-	case "mlp":
-		model = predictor.NewMLPNeuralNetMonteCarlo()
-	case "bivariate":
-		model = predictor.NewBivariatePoissonMonteCarlo()
-	case "zip":
-		model = predictor.NewZeroInflatedPoissonMonteCarlo()
-	default:
-		model = predictor.NewPoissonMonteCarlo()
+	cfg, err := a.seasonConfig(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	p, err := a.newPrediction(modelName, cfg)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if wantsStream(r) {
+		streamPredict(w, r, p, sims)
+		return
 	}
 
-	probs, err := model.Predict(teams, table, remain, sims)
+	res, err := p.run(sims, 0, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"model": model.Name(),
-		"sims":  sims,
-		"probs": probs,
-	})
+	_ = json.NewEncoder(w).Encode(res)
 }
 
 /* ------------ Hard-reset -------------------------------------------------- */
@@ -194,50 +316,110 @@ func (a *App) handleReset(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	n, _ := strconv.Atoi(r.URL.Query().Get("teams"))
-	fmt.Printf("[RESET] Requested team count: %d\n", n)
-	if n < 2 || n%2 != 0 {
-		http.Error(w, "teams must be an even integer ≥2", http.StatusBadRequest)
+	// An optional JSON body {"teams":[...]} lists the clubs explicitly;
+	// otherwise ?teams=N&type= generates N placeholder teams.
+	var body struct {
+		Teams []models.Team `json:"teams"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	// ?schedule= picks the fixture format; double round-robin by default.
+	gen, err := schedule.New(r.URL.Query().Get("schedule"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	typeParam := r.URL.Query().Get("type")
-	initType := "random"
-	if typeParam == "homogeneous" {
-		initType = "homogeneous"
+	newTeams := body.Teams
+	if len(newTeams) == 0 {
+		n, _ := strconv.Atoi(r.URL.Query().Get("teams"))
+		fmt.Printf("[RESET] Requested team count: %d\n", n)
+		if n < 2 {
+			http.Error(w, "teams must be an integer ≥2", http.StatusBadRequest)
+			return
+		}
+
+		typeParam := r.URL.Query().Get("type")
+		initType := "random"
+		if typeParam == "homogeneous" {
+			initType = "homogeneous"
+		}
+		fmt.Printf("[RESET] Initialization type: %s\n", initType)
+
+		for i := 1; i <= n; i++ {
+			team := models.Team{
+				Name: fmt.Sprintf("Team %d", i),
+			}
+			if initType == "homogeneous" {
+				team.Strength = 75 // All teams same strength
+			} else {
+				team.Strength = 50 + rand.Intn(51) // 50-100
+			}
+			newTeams = append(newTeams, team)
+		}
+	} else {
+		if len(newTeams) < 2 {
+			http.Error(w, "teams must list at least 2 teams", http.StatusBadRequest)
+			return
+		}
+		for i, team := range newTeams {
+			newTeams[i] = service.NormalizeTeam(team)
+			if err := service.ValidateTeam(newTeams[i]); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("teams[%d]: %w", i, err))
+				return
+			}
+		}
 	}
-	fmt.Printf("[RESET] Initialization type: %s\n", initType)
 
-	if err := a.repo.ResetMatches(); err != nil {
+	// Teams are shared by every season, so replacing them wipes the
+	// results, fixtures and adjustments of all seasons, not just the
+	// current one.
+	actorRepo := a.repo.WithActor(actorOf(r))
+	if err := actorRepo.ResetMatches(); err != nil {
 		fmt.Printf("[RESET] Error truncating matches: %v\n", err)
-	} else {
-		fmt.Println("[RESET] Matches truncated.")
+		writeServiceError(w, err)
+		return
 	}
-	if err := a.repo.ResetTeams(); err != nil {
+	fmt.Println("[RESET] Matches truncated.")
+	if err := actorRepo.ResetTeams(); err != nil {
 		fmt.Printf("[RESET] Error truncating teams: %v\n", err)
-	} else {
-		fmt.Println("[RESET] Teams truncated.")
+		writeServiceError(w, err)
+		return
 	}
+	fmt.Println("[RESET] Teams truncated.")
 
-	for i := 1; i <= n; i++ {
-		team := models.Team{
-			Name: fmt.Sprintf("Team %d", i),
-		}
-		if initType == "homogeneous" {
-			team.Strength = 75 // All teams same strength
-		} else {
-			team.Strength = 50 + rand.Intn(51) // 50-100
-		}
+	for _, team := range newTeams {
 		fmt.Printf("[RESET] Inserting team: %s, strength: %d\n", team.Name, team.Strength)
-		_ = a.repo.SaveTeam(team)
+		if _, err := a.repo.SaveTeam(team); err != nil {
+			fmt.Printf("[RESET] Error inserting team %s: %v\n", team.Name, err)
+			writeServiceError(w, err)
+			return
+		}
+	}
+	if _, err := a.schedules.Use(gen); err != nil {
+		fmt.Printf("[RESET] Error building %s schedule: %v\n", gen.Name(), err)
+		writeServiceError(w, err)
+		return
 	}
 
-	teams, _ := a.repo.ListTeams()
+	teams, err := a.repo.ListTeams()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
 	fmt.Printf("[RESET] Number of teams after insert: %d\n", len(teams))
 	for _, t := range teams {
 		fmt.Printf("[RESET] Team: id=%d, name=%s, strength=%d\n", t.ID, t.Name, t.Strength)
 	}
-	table, _ := a.repo.GetTable()
+	table, err := a.repo.GetTable()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -248,9 +430,15 @@ func (a *App) handleReset(w http.ResponseWriter, r *http.Request) {
 
 /* ------------ Real Leagues ----------------------------------------------- */
 
+// footballDataKey returns the football-data.org API token, read from the
+// FOOTBALL_DATA_API_KEY environment variable.
+func footballDataKey() string {
+	return os.Getenv("FOOTBALL_DATA_API_KEY")
+}
+
 // Handler to fetch real leagues from football-data.org
 func (a *App) handleRealLeagues(w http.ResponseWriter, r *http.Request) {
-	apiKey := "fb0aff78982d4d7c9835ada840fdabda" // Hardcoded for demo
+	apiKey := footballDataKey()
 	req, _ := http.NewRequest("GET", "https://api.football-data.org/v4/competitions", nil)
 	req.Header.Set("X-Auth-Token", apiKey)
 	resp, err := http.DefaultClient.Do(req)
@@ -271,7 +459,7 @@ func (a *App) handleRealStandings(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "league param required", http.StatusBadRequest)
 		return
 	}
-	apiKey := "fb0aff78982d4d7c9835ada840fdabda"
+	apiKey := footballDataKey()
 	url := fmt.Sprintf("https://api.football-data.org/v4/competitions/%s/standings", league)
 	req, _ := http.NewRequest("GET", url, nil)
 	req.Header.Set("X-Auth-Token", apiKey)
@@ -293,7 +481,7 @@ func (a *App) handleRealFixtures(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "league param required", http.StatusBadRequest)
 		return
 	}
-	apiKey := "fb0aff78982d4d7c9835ada840fdabda"
+	apiKey := footballDataKey()
 	url := fmt.Sprintf("https://api.football-data.org/v4/competitions/%s/matches?status=SCHEDULED", league)
 	req, _ := http.NewRequest("GET", url, nil)
 	req.Header.Set("X-Auth-Token", apiKey)
@@ -316,7 +504,7 @@ func (a *App) handleRealSimulate(w http.ResponseWriter, r *http.Request) {
 	if weekParam != "" {
 		weekLimit, _ = strconv.Atoi(weekParam)
 	}
-	apiKey := "fb0aff78982d4d7c9835ada840fdabda"
+	apiKey := footballDataKey()
 	// Fetch teams (standings)
 	standingsUrl := fmt.Sprintf("https://api.football-data.org/v4/competitions/%s/standings", league)
 	req, _ := http.NewRequest("GET", standingsUrl, nil)
@@ -442,7 +630,7 @@ func (a *App) handleRealPredict(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "league, model, sims param required", http.StatusBadRequest)
 		return
 	}
-	apiKey := "fb0aff78982d4d7c9835ada840fdabda"
+	apiKey := footballDataKey()
 	// Fetch teams (standings)
 	standingsUrl := fmt.Sprintf("https://api.football-data.org/v4/competitions/%s/standings", league)
 	req, _ := http.NewRequest("GET", standingsUrl, nil)
@@ -528,6 +716,8 @@ func (a *App) handleRealPredict(w http.ResponseWriter, r *http.Request) {
 		model = predictor.NewBivariatePoissonMonteCarlo()
 	case "zip":
 		model = predictor.NewZeroInflatedPoissonMonteCarlo()
+	case "dixoncoles":
+		model = predictor.NewDixonColesMonteCarlo()
 	default:
 		model = predictor.NewPoissonMonteCarlo()
 	}
@@ -575,13 +765,8 @@ func (a *App) handleEditMatch(w http.ResponseWriter, r *http.Request) {
 		HomeGoals: req.HomeGoals,
 		AwayGoals: req.AwayGoals,
 	}
-	repoImpl, ok := a.repo.(*repo.PostgresRepo)
-	if !ok {
-		http.Error(w, "edit not supported for this repo type", http.StatusInternalServerError)
-		return
-	}
-	if err := repoImpl.UpdateMatch(match); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if _, err := a.matches.As(actorOf(r)).Update(match); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -596,47 +781,221 @@ func (a *App) handleSimulateAll(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	type WeekResult struct {
-		Week     int                  `json:"week"`
-		Fixtures []models.MatchResult `json:"fixtures"`
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	var simulated []WeekResult
-	for {
-		fixtures, _ := a.nextFixtures()
-		if len(fixtures) == 0 {
-			break
-		}
-		teams, _ := a.repo.ListTeams()
-		var played []models.MatchResult
-		for _, f := range fixtures {
-			home := findTeam(teams, f.HomeTeamID)
-			away := findTeam(teams, f.AwayTeamID)
-			m, _ := a.gen.Generate(home, away)
-			m.Week = f.Week
-			_ = a.repo.SaveMatch(m)
-			played = append(played, m)
-		}
-		simulated = append(simulated, WeekResult{Week: fixtures[0].Week, Fixtures: played})
+	if wantsStream(r) {
+		a.streamSimulated(w, r, gen, -1)
+		return
+	}
+	simulated, err := a.sim.As(actorOf(r)).SimulateAll(gen)
+	if err != nil {
+		a.writeSimulatedError(w, simulated, err)
+		return
+	}
+	a.writeSimulated(w, simulated)
+}
+
+/* ------------ Rewind / undo ---------------------------------------------- */
+
+// Delete every result after ?week=N and return the recalculated table
+func (a *App) handleRewind(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	week, err := strconv.Atoi(r.URL.Query().Get("week"))
+	if err != nil || week < 0 {
+		http.Error(w, "week must be an integer ≥0", http.StatusBadRequest)
+		return
+	}
+	deleted, err := a.sim.As(actorOf(r)).Rewind(week)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	a.writeWithTable(w, map[string]interface{}{
+		"week":    week,
+		"deleted": deleted,
+	})
+}
+
+// Delete the results of the latest played matchday
+func (a *App) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	undone, err := a.sim.As(actorOf(r)).UndoLastWeek()
+	if err != nil {
+		writeServiceError(w, err)
+		return
 	}
-	table, _ := a.repo.GetTable()
+	a.writeWithTable(w, map[string]interface{}{
+		"week":   undone - 1,
+		"undone": undone,
+	})
+}
+
+// writeWithTable responds with resp plus the current table under "table".
+func (a *App) writeWithTable(w http.ResponseWriter, resp map[string]interface{}) {
+	table, err := a.repo.GetTable()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp["table"] = table
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"simulated": simulated,
-		"table":     table,
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeSimulated responds with the played matchdays and the new table.
+func (a *App) writeSimulated(w http.ResponseWriter, simulated []models.WeekResult) {
+	a.writeWithTable(w, map[string]interface{}{"simulated": simulated})
+}
+
+// writeSimulatedError reports a run that failed part way. The matchdays
+// played before the error stay saved, so they come back with the error
+// and the table as it now stands.
+func (a *App) writeSimulatedError(w http.ResponseWriter, simulated []models.WeekResult, err error) {
+	if len(simulated) == 0 {
+		writeServiceError(w, err)
+		return
+	}
+	resp := map[string]interface{}{"error": err.Error(), "simulated": simulated}
+	var simErr *service.SimulationError
+	if errors.As(err, &simErr) {
+		resp["detail"] = simErr
+	}
+	if table, err := a.repo.GetTable(); err == nil {
+		resp["table"] = table
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errorStatus(err))
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// streamSimulated plays up to n matchdays, all of them when n < 0, as
+// Server-Sent Events: a week event with each matchday and the table after
+// it, then done with the body writeSimulated would send. The stream starts
+// with the first matchday, so earlier errors are ordinary responses. A
+// client that disconnects stops the run after the matchday being played.
+func (a *App) streamSimulated(w http.ResponseWriter, r *http.Request, gen interfaces.MatchGenerator, n int) {
+	var stream *sseStream
+	simulated, err := a.sim.As(actorOf(r)).SimulateWeeksFunc(gen, n, func(wr models.WeekResult) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		table, err := a.repo.GetTable()
+		if err != nil {
+			return err
+		}
+		if stream == nil {
+			if stream, err = newSSEStream(w); err != nil {
+				return err
+			}
+		}
+		return stream.send("week", map[string]interface{}{"simulated": wr, "table": table})
 	})
+	if stream == nil {
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if stream, err = newSSEStream(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err != nil {
+		if r.Context().Err() == nil {
+			stream.fail(err)
+		}
+		return
+	}
+	table, err := a.repo.GetTable()
+	if err != nil {
+		stream.fail(err)
+		return
+	}
+	_ = stream.send("done", map[string]interface{}{"simulated": simulated, "table": table})
 }
 
 /* -------------------------------------------------------------------------- */
 /*                               Helper utils                                */
 /* -------------------------------------------------------------------------- */
 
-func findTeam(teams []models.Team, id int) models.Team {
-	for _, t := range teams {
-		if t.ID == id {
-			return t
-		}
+// writeError responds with {"error": msg} and the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// errBadRequest marks errors caused by invalid client input.
+var errBadRequest = errors.New("bad request")
+
+// actorOf names who made a request, for the match audit trail: the client
+// address, after the X-Actor header when one is sent. The server does not
+// authenticate clients, so X-Actor is advisory only; the address is the
+// part the client cannot choose.
+func actorOf(r *http.Request) string {
+	if actor := r.Header.Get("X-Actor"); actor != "" {
+		return fmt.Sprintf("%s (%s)", actor, r.RemoteAddr)
+	}
+	return r.RemoteAddr
+}
+
+// errorStatus picks the HTTP status for an error from the service layer.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest),
+		errors.Is(err, service.ErrInvalidMatch),
+		errors.Is(err, service.ErrInvalidTeam),
+		errors.Is(err, service.ErrInvalidAdjustment),
+		errors.Is(err, service.ErrInvalidPostponement),
+		errors.Is(err, service.ErrInvalidTableQuery),
+		errors.Is(err, service.ErrInvalidHistory),
+		errors.Is(err, service.ErrInvalidJob),
+		errors.Is(err, schedule.ErrUnknownFormat),
+		errors.Is(err, schedule.ErrInvalidCalendar),
+		errors.Is(err, cup.ErrInvalidCup),
+		errors.Is(err, tournament.ErrInvalidTournament),
+		errors.Is(err, pyramid.ErrInvalidPyramid),
+		errors.Is(err, split.ErrInvalidSplit),
+		errors.Is(err, dates.ErrInvalidDates):
+		return http.StatusBadRequest
+	case errors.Is(err, interfaces.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSeasonFinished),
+		errors.Is(err, service.ErrSimulationInProgress),
+		errors.Is(err, service.ErrNothingToUndo),
+		errors.Is(err, service.ErrSeasonStarted),
+		errors.Is(err, service.ErrSeasonNotFinished),
+		errors.Is(err, service.ErrJobFinished),
+		errors.Is(err, interfaces.ErrConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// writeServiceError maps service errors to HTTP responses. Failed
+// matchdays include the step and fixture that broke.
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := errorStatus(err)
+	var simErr *service.SimulationError
+	if !errors.As(err, &simErr) {
+		writeError(w, status, err)
+		return
 	}
-	return models.Team{ID: id, Name: fmt.Sprintf("Team %d", id), Strength: 50}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  simErr.Error(),
+		"detail": simErr,
+	})
 }
 
 /* -------------------------------------------------------------------------- */
@@ -651,15 +1010,33 @@ func main() {
 	if err != nil {
 		log.Fatalf("db connect: %v", err)
 	}
-	app := &App{
-		repo: repository,
-		gen:  service.NewPoissonGenerator(),
+	if footballDataKey() == "" {
+		log.Println("FOOTBALL_DATA_API_KEY is not set; the /real endpoints and history imports will be refused upstream")
 	}
+	app := &App{
+		repo:        repository,
+		sim:         service.NewSimulationService(repository),
+		snapshots:   service.NewSnapshotService(repository),
+		matches:     service.NewMatchService(repository),
+		teams:       service.NewTeamService(repository),
+		schedules:   service.NewScheduleService(repository),
+		pyramid:     service.NewPyramidService(repository),
+		splits:      service.NewSplitService(repository),
+		adjustments: service.NewAdjustmentService(repository),
+		seasonDates: service.NewDatesService(repository),
+		stats:       service.NewStatsService(repository),
+		tables:      service.NewTableService(repository),
+		history:     service.NewHistoryService(repository),
+		jobs:        service.NewJobService(repository, service.DefaultJobWorkers),
+	}
+	app.registerJobs()
+	app.jobs.Start(context.Background())
 
 	http.Handle("/", http.FileServer(http.Dir("web")))
 	http.HandleFunc("/table", app.handleGetTable)
 	http.HandleFunc("/simulate/week", app.handleSimulateWeek)
 	http.HandleFunc("/simulate/weeks", app.handleSimulateWeeks)
+	http.HandleFunc("/simulate/week/live", app.handleSimulateWeekLive)
 	http.HandleFunc("/predict", app.handlePredict)
 	http.HandleFunc("/reset", app.handleReset)
 	http.HandleFunc("/real/leagues", app.handleRealLeagues)
@@ -667,8 +1044,46 @@ func main() {
 	http.HandleFunc("/real/fixtures", app.handleRealFixtures)
 	http.HandleFunc("/real/simulate", app.handleRealSimulate)
 	http.HandleFunc("/real/predict", app.handleRealPredict)
+	http.HandleFunc("/real/history", app.handleRealHistory)
 	http.HandleFunc("/edit_match", app.handleEditMatch)
+	http.HandleFunc("/teams", app.handleTeams)
+	http.HandleFunc("/teams/{id}", app.handleTeam)
+	http.HandleFunc("/teams/{id}/fixtures.ics", app.handleTeamCalendar)
+	http.HandleFunc("/schedule", app.handleSchedule)
+	http.HandleFunc("/schedule/postpone", app.handlePostpone)
+	http.HandleFunc("/dates", app.handleDates)
+	http.HandleFunc("/split", app.handleSplit)
+	http.HandleFunc("/stats/teams/{id}", app.handleTeamStats)
+	http.HandleFunc("/stats/league", app.handleLeagueStats)
+	http.HandleFunc("/h2h", app.handleHeadToHead)
+	http.HandleFunc("/h2h/grid", app.handleResultsGrid)
+	http.HandleFunc("/history", app.handleHistory)
+	http.HandleFunc("/adjustments", app.handleAdjustments)
+	http.HandleFunc("/adjustments/{id}", app.handleAdjustment)
+	http.HandleFunc("/cup/draw", app.handleCupDraw)
+	http.HandleFunc("/cup/simulate", app.handleCupSimulate)
+	http.HandleFunc("/cup/predict", app.handleCupPredict)
+	http.HandleFunc("/tournament/draw", app.handleTournamentDraw)
+	http.HandleFunc("/tournament/simulate", app.handleTournamentSimulate)
+	http.HandleFunc("/tournament/predict", app.handleTournamentPredict)
+	http.HandleFunc("/divisions", app.handleDivisions)
+	http.HandleFunc("/divisions/simulate", app.handleDivisionsSimulate)
+	http.HandleFunc("/divisions/rollover", app.handleDivisionsRollover)
+	http.HandleFunc("/divisions/predict", app.handleDivisionsPredict)
+	http.HandleFunc("/matches", app.handleMatches)
+	http.HandleFunc("/matches/{id}", app.handleMatch)
+	http.HandleFunc("/matches/{id}/history", app.handleMatchHistory)
 	http.HandleFunc("/simulate/all", app.handleSimulateAll)
+	http.HandleFunc("/simulate/rewind", app.handleRewind)
+	http.HandleFunc("/simulate/undo", app.handleUndo)
+	http.HandleFunc("/jobs", app.handleJobs)
+	http.HandleFunc("/jobs/{id}", app.handleJob)
+	http.HandleFunc("/jobs/{id}/cancel", app.handleCancelJob)
+	http.HandleFunc("/snapshots", app.handleSnapshots)
+	http.HandleFunc("/snapshots/{id}", app.handleGetSnapshot)
+	http.HandleFunc("/snapshots/{id}/restore", app.handleRestoreSnapshot)
+	http.HandleFunc("/config/generator", app.handleGeneratorConfig)
+	http.HandleFunc("/config/generator/calibrate", app.handleCalibrateGenerator)
 
 	log.Println("Listening on :8080 …")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/musta/insider-league/internal/interfaces"
+	"github.com/musta/insider-league/internal/models"
+	"github.com/musta/insider-league/internal/service"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Matches                                   */
+/* -------------------------------------------------------------------------- */
+
+// GET lists results (?week=N&team=ID), POST records a fixture's result.
+func (a *App) handleMatches(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		var f service.MatchFilter
+		for key, dst := range map[string]*int{"week": &f.Week, "team": &f.TeamID} {
+			if v := r.URL.Query().Get(key); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 {
+					http.Error(w, key+" must be a positive integer", http.StatusBadRequest)
+					return
+				}
+				*dst = n
+			}
+		}
+		matches, err := a.matches.List(f)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(matches)
+	case http.MethodPost:
+		var m models.MatchResult
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		m.ID = 0
+		created, err := a.matches.As(actorOf(r)).Create(m)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(created)
+	default:
+		http.Error(w, "only GET or POST allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GET returns one result, PUT changes its score, DELETE removes it.
+func (a *App) handleMatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid match id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		m, err := a.matches.Get(id)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m)
+	case http.MethodPut:
+		var m models.MatchResult
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		m.ID = id
+		updated, err := a.matches.As(actorOf(r)).Update(m)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(updated)
+	case http.MethodDelete:
+		if err := a.matches.As(actorOf(r)).Delete(id); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "only GET, PUT or DELETE allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Returns the audit trail of one match, oldest change first
+func (a *App) handleMatchHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid match id", http.StatusBadRequest)
+		return
+	}
+	events, err := a.repo.GetMatchHistory(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if len(events) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("match %d: %w", id, interfaces.ErrNotFound))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/musta/insider-league/internal/interfaces"
+	"github.com/musta/insider-league/internal/models"
+	"github.com/musta/insider-league/internal/predictor"
+	"github.com/musta/insider-league/internal/service"
+	"github.com/musta/insider-league/internal/split"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Predictions                                 */
+/* -------------------------------------------------------------------------- */
+
+// A streamed prediction is played in about predictBatches batches of at
+// least minPredictBatch sims, with a progress event after each.
+const (
+	predictBatches  = 20
+	minPredictBatch = 500
+)
+
+// predictModels lists the /predict model names.
+var predictModels = []string{"poisson", "elo", "bt", "logistic", "mlp", "bivariate", "zip", "dixoncoles", "rest", "xg"}
+
+// predictModel returns the /predict model of the given name, Poisson when
+// the name is empty or unknown.
+func predictModel(name string) predictor.Predictor {
+	switch name {
+	case "elo":
+		return predictor.NewEloMonteCarlo()
+	case "bt":
+		return predictor.NewBradleyTerryMonteCarlo()
+	case "logistic":
+		return predictor.NewLogisticMonteCarlo()
+	case "mlp":
+		return predictor.NewMLPNeuralNetMonteCarlo()
+	case "bivariate":
+		return predictor.NewBivariatePoissonMonteCarlo()
+	case "zip":
+		return predictor.NewZeroInflatedPoissonMonteCarlo()
+	case "dixoncoles":
+		return predictor.NewDixonColesMonteCarlo()
+	case "rest":
+		return predictor.NewRestMonteCarlo()
+	case "xg":
+		return predictor.NewXGMonteCarlo()
+	}
+	return predictor.NewPoissonMonteCarlo()
+}
+
+// predictResult is the answer of /predict, or its progress so far.
+type predictResult struct {
+	Model string           `json:"model"`
+	Sims  int              `json:"sims"` // sims played
+	Total int              `json:"total,omitempty"`
+	Probs map[int]float64  `json:"probs"`
+	Split *splitPrediction `json:"split,omitempty"`
+}
+
+// splitPrediction is the group a team is likely to land in at the split,
+// for a season that has not split yet.
+type splitPrediction struct {
+	Groups []string     `json:"groups"`
+	Odds   []split.Odds `json:"odds"`
+}
+
+// prediction is a /predict run over the season as it stands.
+type prediction struct {
+	model    predictor.Predictor
+	teams    []models.Team
+	table    models.LeagueTable
+	remain   []interfaces.Matchup
+	pending  []models.Adjustment
+	splitCfg models.SplitConfig
+	// newGen makes the generators that play the model out through the
+	// split, before the season splits.
+	newGen interfaces.GeneratorFactory
+}
+
+// newPrediction prepares a prediction with the named model and the
+// season's config.
+func (a *App) newPrediction(modelName string, cfg models.GeneratorConfig) (*prediction, error) {
+	teams, err := a.repo.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+	table, err := a.repo.GetTable()
+	if err != nil {
+		return nil, err
+	}
+	remain, err := a.repo.ListRemainingMatches()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := a.adjustments.Pending()
+	if err != nil {
+		return nil, err
+	}
+	p := &prediction{model: predictModel(modelName), teams: teams, table: table, remain: remain, pending: pending}
+
+	predictor.Configure(p.model, cfg)
+	if h, ok := p.model.(predictor.Historical); ok {
+		played, err := a.repo.GetAllMatches()
+		if err != nil {
+			return nil, err
+		}
+		h.SetPlayed(played)
+	}
+
+	// In a split season only the top group can win the title; before the
+	// split every run has to play the split out itself.
+	if p.splitCfg, err = a.splits.Get(); err != nil {
+		return nil, err
+	}
+	if p.splitCfg.Enabled() && !p.splitCfg.Done() {
+		if modelName == "" {
+			modelName = "poisson"
+		}
+		if _, err := predictor.NewGenerator(modelName, cfg); err != nil {
+			return nil, fmt.Errorf("%w: model %q cannot play out a split season", errBadRequest, modelName)
+		}
+		p.newGen = func() (interfaces.MatchGenerator, error) { return predictor.NewGenerator(modelName, cfg) }
+		return p, nil
+	}
+	// Adjustments for weeks still to come count from the start.
+	if p.table, err = service.ApplyAdjustments(service.NewTableUpdater(), p.table, p.pending); err != nil {
+		return nil, err
+	}
+	if p.splitCfg.Done() {
+		p.table, p.remain = split.TopGroup(p.splitCfg, p.table, p.remain)
+	}
+	return p, nil
+}
+
+// run plays sims simulations in batches of batch, all at once when batch is
+// not positive. After every batch but the last it calls progress, when not
+// nil, with the result so far; an error from progress stops the run.
+func (p *prediction) run(sims, batch int, progress func(predictResult) error) (predictResult, error) {
+	if batch <= 0 || batch > sims {
+		batch = sims
+	}
+	res := predictResult{Model: p.model.Name(), Probs: map[int]float64{}}
+	if p.newGen != nil {
+		res.Split = &splitPrediction{Groups: make([]string, len(p.splitCfg.Groups))}
+		for i, g := range p.splitCfg.Groups {
+			res.Split.Groups[i] = g.Name
+		}
+	}
+	for res.Sims < sims {
+		n := min(batch, sims-res.Sims)
+		if err := p.play(&res, n); err != nil {
+			return predictResult{}, err
+		}
+		if progress != nil && res.Sims < sims {
+			res.Total = sims
+			if err := progress(res); err != nil {
+				return predictResult{}, err
+			}
+			res.Total = 0
+		}
+	}
+	return res, nil
+}
+
+// streamBatch is the batch size of a streamed prediction of sims.
+func streamBatch(sims int) int {
+	return max((sims+predictBatches-1)/predictBatches, minPredictBatch)
+}
+
+// play plays n more sims and folds them into res.
+func (p *prediction) play(res *predictResult, n int) error {
+	done := float64(res.Sims)
+	total := done + float64(n)
+	mix := func(old, batch float64) float64 { return (old*done + batch*float64(n)) / total }
+
+	if p.newGen == nil {
+		probs, err := p.model.Predict(p.teams, p.table, p.remain, n)
+		if err != nil {
+			return err
+		}
+		merged := make(map[int]float64, len(probs))
+		for id, old := range res.Probs {
+			merged[id] = mix(old, probs[id])
+		}
+		for id, pr := range probs {
+			merged[id] = mix(res.Probs[id], pr)
+		}
+		res.Probs = merged
+		res.Sims += n
+		return nil
+	}
+
+	odds, err := split.Predict(p.splitCfg, p.teams, p.table, p.remain, p.pending, p.newGen, service.NewTableUpdater(), n)
+	if err != nil {
+		return err
+	}
+	if res.Split.Odds == nil {
+		res.Split.Odds = odds
+	} else {
+		for i := range odds {
+			o := &res.Split.Odds[i]
+			o.Title = mix(o.Title, odds[i].Title)
+			for g := range o.Groups {
+				o.Groups[g] = mix(o.Groups[g], odds[i].Groups[g])
+			}
+		}
+	}
+	res.Probs = make(map[int]float64, len(res.Split.Odds))
+	for _, o := range res.Split.Odds {
+		if o.Title > 0 {
+			res.Probs[o.TeamID] = o.Title
+		}
+	}
+	res.Sims += n
+	return nil
+}
+
+// streamPredict plays p as Server-Sent Events: a progress event with the
+// probabilities so far after every batch, then the result. A client that
+// disconnects stops the run.
+func streamPredict(w http.ResponseWriter, r *http.Request, p *prediction, sims int) {
+	stream, err := newSSEStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res, err := p.run(sims, streamBatch(sims), func(res predictResult) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		return stream.send("progress", res)
+	})
+	if err != nil {
+		if r.Context().Err() == nil {
+			stream.fail(err)
+		}
+		return
+	}
+	_ = stream.send("result", res)
+}
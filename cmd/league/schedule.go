@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/musta/insider-league/internal/schedule"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Schedule                                  */
+/* -------------------------------------------------------------------------- */
+
+// maxCalendarBytes caps the size of an uploaded calendar.
+const maxCalendarBytes = 1 << 20
+
+// GET returns the fixture list. PUT rebuilds it before the season starts,
+// either in a generated format (?format=) or from an uploaded CSV calendar
+// (Content-Type: text/csv).
+func (a *App) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		sched, err := a.schedules.Get()
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sched)
+	case http.MethodPut:
+		var gen schedule.ScheduleGenerator
+		var err error
+		if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == "text/csv" {
+			gen, err = schedule.ParseCalendar(http.MaxBytesReader(w, r.Body, maxCalendarBytes))
+		} else {
+			gen, err = schedule.New(r.URL.Query().Get("format"))
+		}
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		sched, err := a.schedules.Use(gen)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sched)
+	default:
+		http.Error(w, "only GET or PUT allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Moves an unplayed fixture to a later week, optionally at a set kickoff
+func (a *App) handlePostpone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Week       int       `json:"week"`
+		HomeTeamID int       `json:"home_team_id"`
+		AwayTeamID int       `json:"away_team_id"`
+		ToWeek     int       `json:"to_week"`
+		Kickoff    time.Time `json:"kickoff"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sched, err := a.schedules.Postpone(body.Week, body.HomeTeamID, body.AwayTeamID, body.ToWeek, body.Kickoff)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sched)
+}
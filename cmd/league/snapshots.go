@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Snapshots                                  */
+/* -------------------------------------------------------------------------- */
+
+// pathID parses the {id} path segment.
+func pathID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	return id, err == nil && id > 0
+}
+
+// GET lists snapshots, POST {"name": "..."} saves the current season.
+func (a *App) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		snaps, err := a.snapshots.List()
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(snaps)
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+			http.Error(w, "JSON body with a name required", http.StatusBadRequest)
+			return
+		}
+		snap, err := a.snapshots.Create(req.Name)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(snap)
+	default:
+		http.Error(w, "only GET or POST allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Returns one snapshot with its teams and matches
+func (a *App) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid snapshot id", http.StatusBadRequest)
+		return
+	}
+	snap, err := a.repo.GetSnapshot(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(snap)
+}
+
+// Restores a snapshot into the current season, or with ?fork=true starts
+// a new season (optionally named by ?name=) from it
+func (a *App) handleRestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid snapshot id", http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{"snapshot": id}
+	if r.URL.Query().Get("fork") == "true" {
+		season, err := a.snapshots.As(actorOf(r)).Fork(id, r.URL.Query().Get("name"))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		resp["season"] = season
+	} else {
+		if _, err := a.snapshots.As(actorOf(r)).Restore(id); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+	a.writeWithTable(w, resp)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                            Server-Sent Events                              */
+/* -------------------------------------------------------------------------- */
+
+// wantsStream reports whether the client asked for Server-Sent Events
+// rather than a single JSON response. EventSource always does.
+func wantsStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// sseStream writes Server-Sent Events to one client.
+type sseStream struct {
+	w http.ResponseWriter
+	f http.Flusher
+}
+
+// newSSEStream starts an event stream on w. It fails, before anything is
+// written, when w cannot flush.
+func newSSEStream(w http.ResponseWriter) (*sseStream, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported")
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &sseStream{w: w, f: f}, nil
+}
+
+// send writes one event whose data is v as JSON.
+func (s *sseStream) send(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
+
+// fail ends the stream with an error event, the stream's counterpart of
+// writeServiceError.
+func (s *sseStream) fail(err error) {
+	_ = s.send("error", map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/musta/insider-league/internal/service"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Stats                                    */
+/* -------------------------------------------------------------------------- */
+
+// formGames returns ?form=, or service.DefaultFormGames when it is missing
+// or not a positive number.
+func formGames(r *http.Request) int {
+	if n, err := strconv.Atoi(r.URL.Query().Get("form")); err == nil && n > 0 {
+		return n
+	}
+	return service.DefaultFormGames
+}
+
+// Returns a team's form, streaks, home/away records and timeline
+func (a *App) handleTeamStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "invalid team id", http.StatusBadRequest)
+		return
+	}
+	st, err := a.stats.Team(id, formGames(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(st)
+}
+
+// Returns the season's totals and every team's form in table order
+func (a *App) handleLeagueStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st, err := a.stats.League(formGames(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(st)
+}
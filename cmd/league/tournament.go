@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/musta/insider-league/internal/cup"
+	"github.com/musta/insider-league/internal/models"
+	"github.com/musta/insider-league/internal/service"
+	"github.com/musta/insider-league/internal/tournament"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Tournament                                 */
+/* -------------------------------------------------------------------------- */
+
+// tournamentRequest is the body shared by the /tournament endpoints. Every
+// field is optional: without team_ids all league teams enter, without a
+// draw the groups are drawn from pots.
+type tournamentRequest struct {
+	tournament.Config
+	TeamIDs []int              `json:"team_ids"`
+	Draw    []tournament.Group `json:"draw"`
+}
+
+// readTournamentRequest decodes the body and loads the entrants.
+func (a *App) readTournamentRequest(r *http.Request) (tournamentRequest, []models.Team, error) {
+	req := tournamentRequest{Config: tournament.DefaultConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return req, nil, fmt.Errorf("%w: invalid JSON", errBadRequest)
+	}
+	teams, err := a.entrants(req.TeamIDs)
+	if err != nil {
+		return req, nil, err
+	}
+	if err := req.Config.Validate(len(teams)); err != nil {
+		return req, nil, err
+	}
+	if req.Draw != nil {
+		if err := tournament.ValidateGroups(req.Draw, teams, req.Config); err != nil {
+			return req, nil, err
+		}
+	}
+	return req, teams, nil
+}
+
+// Draws the groups from pots of the league's teams
+func (a *App) handleTournamentDraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req, teams, err := a.readTournamentRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	groups := tournament.DrawGroups(teams, req.Groups, rand.New(rand.NewSource(time.Now().UnixNano())))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"draw":   groups,
+		"rounds": cup.RoundNames(req.Groups * req.Qualifiers),
+	})
+}
+
+// Plays the groups and the knockout to the end with ?generator=
+func (a *App) handleTournamentSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	gen, err := a.generatorFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, teams, err := a.readTournamentRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	groups := req.Draw
+	if groups == nil {
+		groups = tournament.DrawGroups(teams, req.Groups, rng)
+	}
+	res, err := tournament.Simulate(groups, teams, gen, service.NewTableUpdater(), req.Config, rng)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"draw":   groups,
+		"result": res,
+	})
+}
+
+// Monte Carlo odds of leaving the group, reaching each knockout round and
+// winning; without a draw every run makes its own
+func (a *App) handleTournamentPredict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sims := 5000
+	if q := r.URL.Query().Get("sims"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n > 0 {
+			sims = n
+		}
+	}
+	newGen, err := a.generatorFactoryFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, teams, err := a.readTournamentRequest(r)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	odds, err := tournament.Predict(req.Draw, teams, newGen, service.NewTableUpdater(), req.Config, sims, rng)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sims":   sims,
+		"rounds": cup.RoundNames(req.Groups * req.Qualifiers),
+		"odds":   odds,
+	})
+}
@@ -0,0 +1,192 @@
+// Package cup runs knockout competitions: bracket draws, ties over one or
+// two legs, and Monte Carlo odds of reaching each round.
+package cup
+
+import (
+    "errors"
+    "fmt"
+    "math/rand"
+    "sort"
+
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ErrInvalidCup is wrapped by every invalid draw or cup configuration.
+var ErrInvalidCup = errors.New("invalid cup")
+
+// How a tie was decided.
+const (
+    DecidedBye       = "bye"
+    DecidedScore     = "score" // normal time, or aggregate over two legs
+    DecidedAwayGoals = "away goals"
+    DecidedExtraTime = "extra time"
+    DecidedPenalties = "penalties"
+)
+
+// Config describes how ties are played. Ties still level after the
+// enabled tie-breakers go to a penalty shootout.
+type Config struct {
+    Legs      int  `json:"legs"`       // 1 or 2 legs per tie
+    FinalLegs int  `json:"final_legs"` // legs in the final; 0 = 1 (a single match)
+    AwayGoals bool `json:"away_goals"` // away goals break a level aggregate
+    ExtraTime bool `json:"extra_time"` // 30 minutes before penalties
+}
+
+// DefaultConfig is a single-match cup with extra time.
+func DefaultConfig() Config {
+    return Config{Legs: 1, FinalLegs: 1, ExtraTime: true}
+}
+
+// Validate reports the first invalid field.
+func (c Config) Validate() error {
+    if c.Legs != 1 && c.Legs != 2 {
+        return fmt.Errorf("%w: legs must be 1 or 2", ErrInvalidCup)
+    }
+    if c.FinalLegs != 0 && c.FinalLegs != 1 && c.FinalLegs != 2 {
+        return fmt.Errorf("%w: final_legs must be 1 or 2", ErrInvalidCup)
+    }
+    return nil
+}
+
+// legsFor returns the number of legs played in a round with n teams.
+func (c Config) legsFor(n int) int {
+    if n == 2 {
+        if c.FinalLegs == 0 {
+            return 1
+        }
+        return c.FinalLegs
+    }
+    return c.Legs
+}
+
+// Bracket is a first-round draw. Consecutive slots meet (0 v 1, 2 v 3, ...)
+// and winners meet in the same order in later rounds. A slot of 0 is a
+// bye; len(Slots) is a power of two.
+type Bracket struct {
+    Slots []int `json:"slots"`
+}
+
+// Draw builds a bracket for teams, padded with byes to the next power of
+// two. A seeded draw ranks teams by strength and keeps the top seeds apart
+// until the late rounds (1 v 16, 8 v 9, ...); byes go to the best seeds.
+// An open draw shuffles the teams and hands the byes out at random.
+func Draw(teams []models.Team, seeded bool, rng *rand.Rand) (Bracket, error) {
+    if len(teams) < 2 {
+        return Bracket{}, fmt.Errorf("%w: a cup needs at least 2 teams", ErrInvalidCup)
+    }
+    order := make([]models.Team, len(teams))
+    copy(order, teams)
+    if seeded {
+        sort.SliceStable(order, func(i, j int) bool { return order[i].Strength > order[j].Strength })
+        ids := make([]int, len(order))
+        for i, t := range order {
+            ids[i] = t.ID
+        }
+        return Seeded(ids), nil
+    }
+
+    size := bracketSize(len(teams))
+    slots := make([]int, size)
+    rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+    byes := size - len(order)
+    // Each bye takes the second slot of a tie, so no tie is bye v bye.
+    pairs := rng.Perm(size / 2)
+    next := 0
+    for k, p := range pairs {
+        slots[2*p] = order[next].ID
+        next++
+        if k >= byes {
+            slots[2*p+1] = order[next].ID
+            next++
+        }
+    }
+    return Bracket{Slots: slots}, nil
+}
+
+// Seeded places ids in a bracket by seed, ids[0] being the top seed, and
+// pads it with byes for the best seeds.
+func Seeded(ids []int) Bracket {
+    size := bracketSize(len(ids))
+    slots := make([]int, size)
+    for i, seed := range seedOrder(size) {
+        if seed <= len(ids) {
+            slots[i] = ids[seed-1]
+        }
+    }
+    return Bracket{Slots: slots}
+}
+
+// bracketSize is the smallest power of two that holds n teams.
+func bracketSize(n int) int {
+    size := 1
+    for size < n {
+        size *= 2
+    }
+    return size
+}
+
+// seedOrder returns the seed placed in each slot of a bracket of size
+// slots, so that seeds 1 and 2 can only meet in the final.
+func seedOrder(size int) []int {
+    order := []int{1}
+    for n := 2; n <= size; n *= 2 {
+        next := make([]int, 0, n)
+        for _, s := range order {
+            next = append(next, s, n+1-s)
+        }
+        order = next
+    }
+    return order
+}
+
+// Validate checks that b is a usable draw of teams: a power-of-two size,
+// known ids only, no team twice and no tie between two byes.
+func (b Bracket) Validate(teams []models.Team) error {
+    n := len(b.Slots)
+    if n < 2 || n&(n-1) != 0 {
+        return fmt.Errorf("%w: bracket size must be a power of two ≥ 2, got %d", ErrInvalidCup, n)
+    }
+    known := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        known[t.ID] = true
+    }
+    seen := make(map[int]bool, n)
+    for i, id := range b.Slots {
+        if id == 0 {
+            if i%2 == 1 && b.Slots[i-1] == 0 {
+                return fmt.Errorf("%w: tie %d has two byes", ErrInvalidCup, i/2+1)
+            }
+            continue
+        }
+        if !known[id] {
+            return fmt.Errorf("%w: unknown team %d", ErrInvalidCup, id)
+        }
+        if seen[id] {
+            return fmt.Errorf("%w: team %d drawn twice", ErrInvalidCup, id)
+        }
+        seen[id] = true
+    }
+    return nil
+}
+
+// RoundNames names the rounds of a cup for the given number of teams (or
+// bracket slots), first round first.
+func RoundNames(teams int) []string {
+    var names []string
+    for n := bracketSize(teams); n >= 2; n /= 2 {
+        names = append(names, roundName(n))
+    }
+    return names
+}
+
+func roundName(teams int) string {
+    switch teams {
+    case 2:
+        return "Final"
+    case 4:
+        return "Semi-finals"
+    case 8:
+        return "Quarter-finals"
+    }
+    return fmt.Sprintf("Round of %d", teams)
+}
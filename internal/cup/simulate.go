@@ -0,0 +1,117 @@
+package cup
+
+import (
+    "fmt"
+    "math/rand"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Round is every tie of one stage of the cup.
+type Round struct {
+    Name string `json:"name"`
+    Ties []Tie  `json:"ties"`
+}
+
+// Result is a cup played to the end.
+type Result struct {
+    Rounds   []Round `json:"rounds"`
+    WinnerID int     `json:"winner_id"`
+}
+
+// Simulate plays bracket round by round, generating every match with gen.
+func Simulate(b Bracket, teams []models.Team, gen interfaces.MatchGenerator, cfg Config, rng *rand.Rand) (Result, error) {
+    if err := cfg.Validate(); err != nil {
+        return Result{}, err
+    }
+    if err := b.Validate(teams); err != nil {
+        return Result{}, err
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+
+    var res Result
+    alive := append([]int(nil), b.Slots...)
+    for len(alive) > 1 {
+        round := Round{Name: roundName(len(alive))}
+        legs := cfg.legsFor(len(alive))
+        next := make([]int, 0, len(alive)/2)
+        for i := 0; i < len(alive); i += 2 {
+            tie, err := playTie(gen, byID[alive[i]], byID[alive[i+1]], legs, cfg, rng)
+            if err != nil {
+                return res, fmt.Errorf("%s, %d v %d: %w", round.Name, alive[i], alive[i+1], err)
+            }
+            round.Ties = append(round.Ties, tie)
+            next = append(next, tie.WinnerID)
+        }
+        res.Rounds = append(res.Rounds, round)
+        alive = next
+    }
+    res.WinnerID = alive[0]
+    return res, nil
+}
+
+// Odds is one team's chance of reaching each round of the cup.
+type Odds struct {
+    TeamID int       `json:"team_id"`
+    Reach  []float64 `json:"reach"` // Reach[i]: plays in round i (byes count)
+    Win    float64   `json:"win"`
+}
+
+// Predict simulates the cup sims times, each run with a generator fresh
+// from newGen. With a nil bracket every run makes a fresh draw (seeded or
+// open); otherwise the given draw is kept.
+func Predict(b *Bracket, seeded bool, teams []models.Team, newGen interfaces.GeneratorFactory, cfg Config, sims int, rng *rand.Rand) ([]Odds, error) {
+    if sims < 1 {
+        return nil, fmt.Errorf("%w: sims must be positive", ErrInvalidCup)
+    }
+    reached := make(map[int][]int, len(teams))
+    wins := make(map[int]int, len(teams))
+    var rounds int
+    for s := 0; s < sims; s++ {
+        draw := b
+        if draw == nil {
+            d, err := Draw(teams, seeded, rng)
+            if err != nil {
+                return nil, err
+            }
+            draw = &d
+        }
+        gen, err := newGen()
+        if err != nil {
+            return nil, err
+        }
+        res, err := Simulate(*draw, teams, gen, cfg, rng)
+        if err != nil {
+            return nil, err
+        }
+        rounds = len(res.Rounds)
+        for i, round := range res.Rounds {
+            for _, tie := range round.Ties {
+                for _, id := range []int{tie.HomeTeamID, tie.AwayTeamID} {
+                    if id == 0 {
+                        continue
+                    }
+                    if reached[id] == nil {
+                        reached[id] = make([]int, rounds)
+                    }
+                    reached[id][i]++
+                }
+            }
+        }
+        wins[res.WinnerID]++
+    }
+
+    odds := make([]Odds, 0, len(teams))
+    for _, t := range teams {
+        o := Odds{TeamID: t.ID, Reach: make([]float64, rounds), Win: float64(wins[t.ID]) / float64(sims)}
+        for i, n := range reached[t.ID] {
+            o.Reach[i] = float64(n) / float64(sims)
+        }
+        odds = append(odds, o)
+    }
+    return odds, nil
+}
@@ -0,0 +1,158 @@
+package cup
+
+import (
+    "math/rand"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Extra time is a third of a match: goals from a generated match are each
+// kept with this probability, which turns a Poisson λ into λ/3.
+const extraTimeShare = 1.0 / 3.0
+
+// penaltyConversion is the chance of scoring a spot kick.
+const penaltyConversion = 0.75
+
+// Score is a pair of goal counts, home side first.
+type Score struct {
+    Home int `json:"home"`
+    Away int `json:"away"`
+}
+
+// Tie is one knockout pairing. HomeTeamID hosts the first leg. For two
+// legs, ExtraTime is played at the second leg's venue but is still given
+// from HomeTeamID's point of view, like Aggregate.
+type Tie struct {
+    HomeTeamID int                  `json:"home_team_id"`
+    AwayTeamID int                  `json:"away_team_id"`
+    Legs       []models.MatchResult `json:"legs,omitempty"`
+    Aggregate  Score                `json:"aggregate"`
+    ExtraTime  *Score               `json:"extra_time,omitempty"`
+    Penalties  *Score               `json:"penalties,omitempty"`
+    WinnerID   int                  `json:"winner_id"`
+    DecidedBy  string               `json:"decided_by"`
+}
+
+// playTie plays home v away over legs matches and breaks a level result
+// with away goals, extra time and penalties as cfg allows.
+func playTie(gen interfaces.MatchGenerator, home, away models.Team, legs int, cfg Config, rng *rand.Rand) (Tie, error) {
+    tie := Tie{HomeTeamID: home.ID, AwayTeamID: away.ID}
+    switch {
+    case home.ID == 0:
+        tie.WinnerID, tie.DecidedBy = away.ID, DecidedBye
+        return tie, nil
+    case away.ID == 0:
+        tie.WinnerID, tie.DecidedBy = home.ID, DecidedBye
+        return tie, nil
+    }
+
+    first, err := gen.Generate(home, away)
+    if err != nil {
+        return tie, err
+    }
+    tie.Legs = append(tie.Legs, first)
+    tie.Aggregate = Score{first.HomeGoals, first.AwayGoals}
+    // Goals scored away from home by each side, for the away goals rule.
+    homeAway, awayAway := 0, first.AwayGoals
+    // The venue of the last match, where extra time is played.
+    lastHome, lastAway, swapped := home, away, false
+
+    if legs == 2 {
+        second, err := gen.Generate(away, home)
+        if err != nil {
+            return tie, err
+        }
+        tie.Legs = append(tie.Legs, second)
+        tie.Aggregate.Home += second.AwayGoals
+        tie.Aggregate.Away += second.HomeGoals
+        homeAway = second.AwayGoals
+        lastHome, lastAway, swapped = away, home, true
+    }
+
+    if decide(&tie, tie.Aggregate, DecidedScore) {
+        return tie, nil
+    }
+    if legs == 2 && cfg.AwayGoals && homeAway != awayAway {
+        if homeAway > awayAway {
+            tie.WinnerID = home.ID
+        } else {
+            tie.WinnerID = away.ID
+        }
+        tie.DecidedBy = DecidedAwayGoals
+        return tie, nil
+    }
+
+    if cfg.ExtraTime {
+        et, err := gen.Generate(lastHome, lastAway)
+        if err != nil {
+            return tie, err
+        }
+        extra := Score{thin(et.HomeGoals, rng), thin(et.AwayGoals, rng)}
+        if swapped {
+            extra.Home, extra.Away = extra.Away, extra.Home
+        }
+        tie.ExtraTime = &extra
+        tie.Aggregate.Home += extra.Home
+        tie.Aggregate.Away += extra.Away
+        if decide(&tie, tie.Aggregate, DecidedExtraTime) {
+            return tie, nil
+        }
+        // Goals in extra time of a second leg count double for the
+        // visitors, i.e. the first leg's hosts.
+        if legs == 2 && cfg.AwayGoals && extra.Home > 0 {
+            tie.WinnerID, tie.DecidedBy = home.ID, DecidedAwayGoals
+            return tie, nil
+        }
+    }
+
+    pens := shootout(rng)
+    tie.Penalties = &pens
+    decide(&tie, pens, DecidedPenalties)
+    return tie, nil
+}
+
+// decide sets the winner from s unless it is level.
+func decide(tie *Tie, s Score, by string) bool {
+    switch {
+    case s.Home > s.Away:
+        tie.WinnerID = tie.HomeTeamID
+    case s.Away > s.Home:
+        tie.WinnerID = tie.AwayTeamID
+    default:
+        return false
+    }
+    tie.DecidedBy = by
+    return true
+}
+
+// thin keeps each of n goals with probability extraTimeShare.
+func thin(n int, rng *rand.Rand) int {
+    kept := 0
+    for i := 0; i < n; i++ {
+        if rng.Float64() < extraTimeShare {
+            kept++
+        }
+    }
+    return kept
+}
+
+// shootout plays five kicks each, then sudden death, until one side leads.
+func shootout(rng *rand.Rand) Score {
+    var s Score
+    kick := func() int {
+        if rng.Float64() < penaltyConversion {
+            return 1
+        }
+        return 0
+    }
+    for i := 0; i < 5; i++ {
+        s.Home += kick()
+        s.Away += kick()
+    }
+    for s.Home == s.Away {
+        s.Home += kick()
+        s.Away += kick()
+    }
+    return s
+}
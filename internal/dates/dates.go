@@ -0,0 +1,213 @@
+// Package dates puts a season's matchdays on the calendar: kickoff times,
+// rest days between games and iCalendar feeds.
+package dates
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "time"
+    _ "time/tzdata" // timezones do not depend on the host's zoneinfo
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ErrInvalidDates is wrapped by every invalid SeasonDates.
+var ErrInvalidDates = errors.New("invalid dates")
+
+// CongestionWindow is how far back Rest counts a side's recent games.
+const CongestionWindow = 14 * 24 * time.Hour
+
+// plan is a parsed SeasonDates.
+type plan struct {
+    start         time.Time // midnight of matchday 1 in loc
+    loc           *time.Location
+    kickoff       time.Duration // since local midnight
+    midweekKick   time.Duration
+    daysBetween   int
+    midweekOffset int
+    midweek       map[int]bool
+}
+
+// Validate checks d, which must have its defaults filled in.
+func Validate(d models.SeasonDates) error {
+    _, err := parse(d)
+    return err
+}
+
+func parse(d models.SeasonDates) (plan, error) {
+    p := plan{daysBetween: d.DaysBetween, midweekOffset: d.MidweekOffset, midweek: make(map[int]bool)}
+    var err error
+    if p.loc, err = time.LoadLocation(d.Timezone); err != nil {
+        return p, fmt.Errorf("%w: unknown timezone %q", ErrInvalidDates, d.Timezone)
+    }
+    if p.start, err = time.ParseInLocation(time.DateOnly, d.StartDate, p.loc); err != nil {
+        return p, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidDates)
+    }
+    if p.kickoff, err = clock(d.Kickoff); err != nil {
+        return p, fmt.Errorf("%w: kickoff must be HH:MM", ErrInvalidDates)
+    }
+    if p.midweekKick, err = clock(d.MidweekKickoff); err != nil {
+        return p, fmt.Errorf("%w: midweek_kickoff must be HH:MM", ErrInvalidDates)
+    }
+    switch {
+    case d.DaysBetween < 1:
+        return p, fmt.Errorf("%w: days_between must be ≥ 1", ErrInvalidDates)
+    case len(d.MidweekWeeks) > 0 && (d.MidweekOffset < 1 || d.MidweekOffset >= d.DaysBetween):
+        return p, fmt.Errorf("%w: midweek_offset must be between 1 and days_between-1", ErrInvalidDates)
+    }
+    for _, w := range d.MidweekWeeks {
+        if w < 2 {
+            return p, fmt.Errorf("%w: matchday 1 is played on start_date, midweek weeks start at 2", ErrInvalidDates)
+        }
+        p.midweek[w] = true
+    }
+    return p, nil
+}
+
+// clock parses an HH:MM time of day.
+func clock(s string) (time.Duration, error) {
+    t, err := time.Parse("15:04", s)
+    if err != nil {
+        return 0, err
+    }
+    return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// kickoffs returns the kickoff of every matchday up to weeks, indexed by
+// week; index 0 is unused.
+func (p plan) kickoffs(weeks int) []time.Time {
+    out := make([]time.Time, weeks+1)
+    var prev, weekend time.Time
+    for w := 1; w <= weeks; w++ {
+        var day time.Time
+        switch {
+        case w == 1:
+            day, weekend = p.start, p.start
+        case p.midweek[w]:
+            day = prev.AddDate(0, 0, p.midweekOffset)
+        default:
+            weekend = weekend.AddDate(0, 0, p.daysBetween)
+            for !weekend.After(prev) {
+                weekend = weekend.AddDate(0, 0, p.daysBetween)
+            }
+            day = weekend
+        }
+        prev = day
+        kick := p.kickoff
+        if p.midweek[w] {
+            kick = p.midweekKick
+        }
+        // Adding to the calendar date keeps the wall-clock time across
+        // daylight-saving changes.
+        out[w] = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, p.loc).Add(kick)
+    }
+    return out
+}
+
+// Matchdays returns the calendar kickoff of every matchday up to weeks,
+// indexed by week, or nil when d is not enabled or invalid.
+func Matchdays(d models.SeasonDates, weeks int) []time.Time {
+    if !d.Enabled() {
+        return nil
+    }
+    p, err := parse(d.WithDefaults())
+    if err != nil {
+        return nil
+    }
+    return p.kickoffs(weeks)
+}
+
+// Fill gives every fixture without a kickoff of its own the kickoff of
+// its matchday. Fixtures are left alone when d is not enabled.
+func Fill(d models.SeasonDates, fixtures []interfaces.Matchup) {
+    days := Matchdays(d, lastWeek(fixtures))
+    if days == nil {
+        return
+    }
+    for i := range fixtures {
+        if fixtures[i].Kickoff.IsZero() && fixtures[i].Week >= 1 {
+            fixtures[i].Kickoff = days[fixtures[i].Week]
+        }
+    }
+}
+
+// Unfill clears the kickoffs that merely repeat their matchday's, leaving
+// those set for a single fixture. It is the inverse of Fill.
+func Unfill(d models.SeasonDates, fixtures []interfaces.Matchup) {
+    days := Matchdays(d, lastWeek(fixtures))
+    if days == nil {
+        return
+    }
+    for i := range fixtures {
+        if f := fixtures[i]; f.Week >= 1 && f.Kickoff.Equal(days[f.Week]) {
+            fixtures[i].Kickoff = time.Time{}
+        }
+    }
+}
+
+// lastWeek returns the highest week of fixtures.
+func lastWeek(fixtures []interfaces.Matchup) int {
+    weeks := 0
+    for _, f := range fixtures {
+        if f.Week > weeks {
+            weeks = f.Week
+        }
+    }
+    return weeks
+}
+
+// Rest is how rested both sides are going into a fixture.
+type Rest struct {
+    HomeDays   float64 // days since the home side's previous game; 0 when unknown
+    AwayDays   float64
+    HomeRecent int // games the home side played in the CongestionWindow before kickoff
+    AwayRecent int
+}
+
+// RestBefore returns the Rest of every fixture in remaining, in order,
+// counting the played results and the remaining fixtures before it.
+// Fixtures without a kickoff get a zero Rest.
+func RestBefore(played []models.MatchResult, remaining []interfaces.Matchup) []Rest {
+    games := make(map[int][]time.Time)
+    add := func(teamID int, at time.Time) {
+        if !at.IsZero() {
+            games[teamID] = append(games[teamID], at)
+        }
+    }
+    for _, m := range played {
+        add(m.HomeTeamID, m.Kickoff)
+        add(m.AwayTeamID, m.Kickoff)
+    }
+    for _, f := range remaining {
+        add(f.HomeTeamID, f.Kickoff)
+        add(f.AwayTeamID, f.Kickoff)
+    }
+    for _, ts := range games {
+        sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+    }
+
+    before := func(teamID int, at time.Time) (float64, int) {
+        days, recent := 0.0, 0
+        for _, t := range games[teamID] {
+            if !t.Before(at) {
+                break
+            }
+            days = at.Sub(t).Hours() / 24
+            if at.Sub(t) <= CongestionWindow {
+                recent++
+            }
+        }
+        return days, recent
+    }
+    out := make([]Rest, len(remaining))
+    for i, f := range remaining {
+        if f.Kickoff.IsZero() {
+            continue
+        }
+        out[i].HomeDays, out[i].HomeRecent = before(f.HomeTeamID, f.Kickoff)
+        out[i].AwayDays, out[i].AwayRecent = before(f.AwayTeamID, f.Kickoff)
+    }
+    return out
+}
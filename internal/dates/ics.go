@@ -0,0 +1,83 @@
+package dates
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strings"
+    "time"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// MatchLength is how long a fixture's calendar event lasts.
+const MatchLength = 2 * time.Hour
+
+// icsTime is the UTC date-time format of iCalendar.
+const icsTime = "20060102T150405Z"
+
+// WriteICS writes the dated fixtures of teamID in season as an iCalendar
+// (RFC 5545) feed. Played fixtures carry their score in the summary;
+// fixtures without a kickoff are left out.
+func WriteICS(w io.Writer, season, teamID int, teams []models.Team, fixtures []interfaces.Matchup, results []models.MatchResult) error {
+    names := make(map[int]string, len(teams))
+    for _, t := range teams {
+        names[t.ID] = t.Name
+    }
+    scores := make(map[interfaces.Matchup]models.MatchResult, len(results))
+    for _, m := range results {
+        scores[interfaces.Matchup{Week: m.Week, HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID}] = m
+    }
+
+    bw := bufio.NewWriter(w)
+    line := func(s string) {
+        // Content lines are folded at 75 octets.
+        for len(s) > 75 {
+            cut := 75
+            for cut > 0 && s[cut]&0xC0 == 0x80 { // keep UTF-8 sequences whole
+                cut--
+            }
+            bw.WriteString(s[:cut] + "\r\n")
+            s = " " + s[cut:]
+        }
+        bw.WriteString(s + "\r\n")
+    }
+    line("BEGIN:VCALENDAR")
+    line("VERSION:2.0")
+    line("PRODID:-//insider-league//fixtures//EN")
+    line("CALSCALE:GREGORIAN")
+    line("X-WR-CALNAME:" + escape(names[teamID]))
+    stamp := time.Now().UTC().Format(icsTime)
+    for _, f := range fixtures {
+        if f.Kickoff.IsZero() || (f.HomeTeamID != teamID && f.AwayTeamID != teamID) {
+            continue
+        }
+        summary := fmt.Sprintf("%s vs %s", names[f.HomeTeamID], names[f.AwayTeamID])
+        key := interfaces.Matchup{Week: f.Week, HomeTeamID: f.HomeTeamID, AwayTeamID: f.AwayTeamID}
+        if m, ok := scores[key]; ok {
+            summary = fmt.Sprintf("%s %d-%d %s", names[f.HomeTeamID], m.HomeGoals, m.AwayGoals, names[f.AwayTeamID])
+        }
+        desc := fmt.Sprintf("Matchday %d", f.Week)
+        if f.OriginalWeek != 0 {
+            desc += fmt.Sprintf(", postponed from matchday %d", f.OriginalWeek)
+        }
+        line("BEGIN:VEVENT")
+        // The UID uses the matchday the fixture was first scheduled in, so
+        // a postponed fixture keeps it and calendar apps move the event.
+        line(fmt.Sprintf("UID:%d-%d-%d-%d@insider-league", season, f.Round(), f.HomeTeamID, f.AwayTeamID))
+        line("DTSTAMP:" + stamp)
+        line("DTSTART:" + f.Kickoff.UTC().Format(icsTime))
+        line("DTEND:" + f.Kickoff.Add(MatchLength).UTC().Format(icsTime))
+        line("SUMMARY:" + escape(summary))
+        line("DESCRIPTION:" + escape(desc))
+        line("END:VEVENT")
+    }
+    line("END:VCALENDAR")
+    return bw.Flush()
+}
+
+// escape escapes an iCalendar TEXT value.
+func escape(s string) string {
+    return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
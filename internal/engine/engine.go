@@ -0,0 +1,231 @@
+// Package engine plays a match minute by minute. Each minute both sides
+// may create a chance, whose quality is its xG and which goes in with that
+// probability, and may be shown a card. The score and the players sent off
+// change how the rest of the game is played, so goals and red cards land
+// at particular minutes rather than being drawn as a final score.
+package engine
+
+import (
+    "math"
+    "math/rand"
+    "time"
+
+    "github.com/musta/insider-league/internal/models"
+)
+
+const (
+    // XGPerShot is the mean quality of a chance; a side expected to score λ
+    // creates about λ/XGPerShot of them.
+    XGPerShot = 0.11
+    // MaxShotXG caps the quality of a single chance.
+    MaxShotXG = 0.9
+    // SavedShare is the share of missed chances that still hit the target.
+    SavedShare = 0.3
+    // CornerShare is the share of missed chances that win a corner.
+    CornerShare = 0.3
+    // YellowCardsPerGame and RedCardsPerGame are each side's card rates.
+    YellowCardsPerGame = 1.8
+    RedCardsPerGame    = 0.08
+    // MaxSentOff is how many players a side can lose; it gets no more red
+    // cards after that.
+    MaxSentOff = 4
+    // ManDown scales a side's scoring rate for every player it is short,
+    // ManUp for every player its opponent is short.
+    ManDown = 0.7
+    ManUp   = 1.2
+    // ChasingFrom is the minute from which the score changes how the sides
+    // play: the side behind pushes on by ChasingBoost and the side ahead
+    // sits back by Protecting.
+    ChasingFrom  = 70
+    ChasingBoost = 1.25
+    Protecting   = 0.85
+    // LateRise is how much more often goals come at the end of a match than
+    // at kickoff; the rate rises linearly in between.
+    LateRise = 0.3
+)
+
+// Stoppage time ranges, in minutes.
+const (
+    minAddedFirst, maxAddedFirst   = 1, 4
+    minAddedSecond, maxAddedSecond = 2, 6
+)
+
+// expectedMinutes is the mean length of a match with stoppage time, over
+// which each side's λ is spread.
+const expectedMinutes = 90 + (minAddedFirst+maxAddedFirst)/2.0 + (minAddedSecond+maxAddedSecond)/2.0
+
+// Engine plays matches minute by minute with goal rates from a
+// GeneratorConfig. Its Dispersion is not used: the game state already
+// spreads the scores.
+type Engine struct {
+    cfg models.GeneratorConfig
+    rng *rand.Rand
+}
+
+// New returns an Engine whose sides score at the rates of cfg.Lambdas.
+func New(cfg models.GeneratorConfig) *Engine {
+    return &Engine{cfg: cfg, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Generate implements interfaces.MatchGenerator.
+func (e *Engine) Generate(home, away models.Team) (models.MatchResult, error) {
+    m, _, err := e.Play(home, away)
+    return m, err
+}
+
+// Play implements interfaces.TimelineGenerator. The result carries the
+// goals in order and the stats of the match.
+func (e *Engine) Play(home, away models.Team) (models.MatchResult, []models.TimelineEvent, error) {
+    hl, al := e.cfg.Lambdas(home.Strength, away.Strength)
+    g := &game{
+        rng:   e.rng,
+        ids:   [2]int{home.ID, away.ID},
+        rates: [2]float64{hl / expectedMinutes, al / expectedMinutes},
+    }
+
+    for half := 0; half < 2; half++ {
+        start, end := 1, 45
+        added := minAddedFirst + e.rng.Intn(maxAddedFirst-minAddedFirst+1)
+        kind := models.TimelineHalfTime
+        if half == 1 {
+            start, end = 46, 90
+            added = minAddedSecond + e.rng.Intn(maxAddedSecond-minAddedSecond+1)
+            kind = models.TimelineFullTime
+        }
+        for m := start; m <= end+added; m++ {
+            minute, extra := m, 0
+            if m > end {
+                minute, extra = end, m-end
+            }
+            g.minute(minute, extra)
+        }
+        g.event(end, added, kind, -1)
+    }
+
+    m := models.MatchResult{
+        HomeTeamID: home.ID,
+        AwayTeamID: away.ID,
+        HomeGoals:  g.goals[0],
+        AwayGoals:  g.goals[1],
+        Goals:      g.scored,
+        Stats:      g.stats(hl, al),
+    }
+    return m, g.events, nil
+}
+
+// game is the state of a match being played; index 0 is the home side.
+type game struct {
+    rng     *rand.Rand
+    ids     [2]int
+    rates   [2]float64 // goals per minute before the game state is applied
+    goals   [2]int
+    sentOff [2]int
+    xg      [2]float64
+    shots   [2]int
+    onTgt   [2]int
+    corners [2]int
+    yellows [2]int
+    scored  []models.Goal
+    events  []models.TimelineEvent
+}
+
+// minute plays one minute for both sides.
+func (g *game) minute(minute, added int) {
+    for side := 0; side < 2; side++ {
+        g.chance(side, minute, added)
+        g.cards(side, minute, added)
+    }
+}
+
+// chance lets side create a chance at its current scoring rate.
+func (g *game) chance(side, minute, added int) {
+    p := math.Min(g.rate(side, minute)/XGPerShot, 0.9)
+    if g.rng.Float64() >= p {
+        return
+    }
+    q := math.Min(g.rng.ExpFloat64()*XGPerShot, MaxShotXG)
+    g.xg[side] += q
+    g.shots[side]++
+    if g.rng.Float64() < q {
+        g.onTgt[side]++
+        g.goals[side]++
+        g.scored = append(g.scored, models.Goal{Minute: minute, AddedTime: added, TeamID: g.ids[side]})
+        g.event(minute, added, models.TimelineGoal, side)
+        return
+    }
+    if g.rng.Float64() < SavedShare {
+        g.onTgt[side]++
+    }
+    if g.rng.Float64() < CornerShare {
+        g.corners[side]++
+    }
+}
+
+// cards may show side a yellow or a red card.
+func (g *game) cards(side, minute, added int) {
+    if g.rng.Float64() < YellowCardsPerGame/90 {
+        g.yellows[side]++
+        g.event(minute, added, models.TimelineYellowCard, side)
+    }
+    if g.sentOff[side] < MaxSentOff && g.rng.Float64() < RedCardsPerGame/90 {
+        g.sentOff[side]++
+        g.event(minute, added, models.TimelineRedCard, side)
+    }
+}
+
+// rate is side's chance of scoring in minute given the game state.
+func (g *game) rate(side, minute int) float64 {
+    other := 1 - side
+    r := g.rates[side] * (1 - LateRise/2 + LateRise*float64(minute)/90)
+    r *= math.Pow(ManDown, float64(g.sentOff[side])) * math.Pow(ManUp, float64(g.sentOff[other]))
+    if minute >= ChasingFrom {
+        switch {
+        case g.goals[side] < g.goals[other]:
+            r *= ChasingBoost
+        case g.goals[side] > g.goals[other]:
+            r *= Protecting
+        }
+    }
+    return r
+}
+
+// event records an event of kind for side, or for neither when side is -1.
+func (g *game) event(minute, added int, kind string, side int) {
+    ev := models.TimelineEvent{
+        Minute:    minute,
+        AddedTime: added,
+        Kind:      kind,
+        HomeGoals: g.goals[0],
+        AwayGoals: g.goals[1],
+    }
+    if side >= 0 {
+        ev.TeamID = g.ids[side]
+    }
+    g.events = append(g.events, ev)
+}
+
+// stats returns the match stats. Possession follows the sides' expected
+// goals hl and al, pulled towards an even split.
+func (g *game) stats(hl, al float64) *models.MatchStats {
+    home := 50
+    if hl+al > 0 {
+        home = int(math.Round(50 + (hl/(hl+al)-0.5)*60))
+    }
+    home = max(30, min(70, home))
+    return &models.MatchStats{
+        HomeXG:            math.Round(g.xg[0]*100) / 100,
+        AwayXG:            math.Round(g.xg[1]*100) / 100,
+        HomeShots:         g.shots[0],
+        AwayShots:         g.shots[1],
+        HomeShotsOnTarget: g.onTgt[0],
+        AwayShotsOnTarget: g.onTgt[1],
+        HomePossession:    home,
+        AwayPossession:    100 - home,
+        HomeCorners:       g.corners[0],
+        AwayCorners:       g.corners[1],
+        HomeYellowCards:   g.yellows[0],
+        AwayYellowCards:   g.yellows[1],
+        HomeRedCards:      g.sentOff[0],
+        AwayRedCards:      g.sentOff[1],
+    }
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import "errors"
+
+var (
+    // ErrNotFound is returned when the requested record does not exist.
+    ErrNotFound = errors.New("not found")
+    // ErrConflict is returned when a write would duplicate existing data,
+    // e.g. a fixture that has already been played.
+    ErrConflict = errors.New("conflicts with existing data")
+    // ErrLocked is returned when another transaction holds the season lock.
+    ErrLocked = errors.New("season is locked by another transaction")
+)
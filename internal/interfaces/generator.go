@@ -7,3 +7,25 @@ type MatchGenerator interface {
     // Generate takes two teams and returns the MatchResult.
     Generate(home, away models.Team) (models.MatchResult, error)
 }
+
+// GeneratorFactory returns a fresh MatchGenerator. Monte Carlo runs build
+// one per simulation, so a generator whose matches depend on those it
+// generated before, such as Elo's moving ratings, starts every run from
+// the same place.
+type GeneratorFactory func() (MatchGenerator, error)
+
+// StatefulGenerator is a MatchGenerator whose matches depend on those it
+// generated before. Reset forgets them, so the next match is generated as
+// if it were the first, without building a new generator.
+type StatefulGenerator interface {
+    MatchGenerator
+    Reset()
+}
+
+// TimelineGenerator is a MatchGenerator that plays a match minute by
+// minute and also returns what happened when.
+type TimelineGenerator interface {
+    MatchGenerator
+    // Play returns the result of the match and its events in order.
+    Play(home, away models.Team) (models.MatchResult, []models.TimelineEvent, error)
+}
@@ -1,8 +1,24 @@
 package interfaces
 
+import "time"
+
 // Matchup represents one remaining fixture for prediction.
 type Matchup struct {
     Week       int
     HomeTeamID int
     AwayTeamID int
+    // OriginalWeek is the week a postponed fixture was first scheduled
+    // in; 0 when it has not been moved.
+    OriginalWeek int `json:",omitempty"`
+    // Kickoff is when the fixture starts; zero when the season has no
+    // dates.
+    Kickoff time.Time `json:",omitzero"`
+}
+
+// Round returns the week f was originally scheduled in.
+func (f Matchup) Round() int {
+    if f.OriginalWeek != 0 {
+        return f.OriginalWeek
+    }
+    return f.Week
 }
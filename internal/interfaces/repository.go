@@ -1,18 +1,159 @@
 package interfaces
 
-import "github.com/musta/insider-league/internal/models"
+import (
+    "time"
+
+    "github.com/musta/insider-league/internal/models"
+)
 
 // Repository abstracts data persistence.
 type Repository interface {
     ListTeams() ([]models.Team, error)
     SaveMatch(m models.MatchResult) error
+    // UpdateMatch changes the score of a match, found by ID or, when ID is
+    // zero, by week and teams. It returns ErrNotFound when there is none.
+    UpdateMatch(m models.MatchResult) error
+    // DeleteMatch removes a result so its fixture is scheduled again.
+    DeleteMatch(id int) error
+    // GetMatch returns one of the current season's results, or ErrNotFound.
+    GetMatch(id int) (models.MatchResult, error)
     GetTable() (models.LeagueTable, error)
     GetAllMatches() ([]models.MatchResult, error)
     ResetMatches() error
     ResetTeams() error
-    SaveTeam(team models.Team) error
+    // SaveTeam inserts a new team and returns it with its assigned ID.
+    SaveTeam(team models.Team) (models.Team, error)
+    // GetTeam returns one team, or ErrNotFound.
+    GetTeam(id int) (models.Team, error)
+    // UpdateTeam overwrites every field of the team with team.ID.
+    UpdateTeam(team models.Team) error
+    // DeleteTeam removes a team. It fails with ErrConflict while the team
+    // still has results.
+    DeleteTeam(id int) error
+    // DeleteMatchesAfter removes the current season's results played after
+    // week and returns how many were deleted.
+    DeleteMatchesAfter(week int) (int, error)
 
 
+    // ListFixtures returns the full schedule, played or not.
+    ListFixtures() ([]Matchup, error)
     // ListRemainingMatches returns all future fixtures.
     ListRemainingMatches() ([]Matchup, error)
+    // SaveFixtures replaces the current season's fixture list, built in
+    // the named format.
+    SaveFixtures(format string, fixtures []Matchup) error
+    // ScheduleFormat returns the format of the current season's fixtures.
+    ScheduleFormat() (string, error)
+    // GetSplit returns the current season's split format; its Groups are
+    // empty when the season does not split.
+    GetSplit() (models.SplitConfig, error)
+    // SaveSplit replaces the current season's split format and group
+    // members; a config without groups removes it.
+    SaveSplit(cfg models.SplitConfig) error
+    // GetDates returns the current season's calendar; it is not Enabled
+    // when the season has none. ListFixtures fills in the kickoffs it
+    // implies.
+    GetDates() (models.SeasonDates, error)
+    // SaveDates replaces the current season's calendar; SeasonDates
+    // without a start date removes it.
+    SaveDates(d models.SeasonDates) error
+
+    // ListAdjustments returns the current season's points adjustments and
+    // walkovers ordered by week.
+    ListAdjustments() ([]models.Adjustment, error)
+    // GetAdjustment returns one adjustment, or ErrNotFound.
+    GetAdjustment(id int) (models.Adjustment, error)
+    // SaveAdjustment stores a new adjustment and returns it with ID and
+    // CreatedAt filled in.
+    SaveAdjustment(a models.Adjustment) (models.Adjustment, error)
+    // DeleteAdjustment removes an adjustment, or returns ErrNotFound.
+    DeleteAdjustment(id int) error
+
+    // ListMeetings returns every game between two teams: imported
+    // results matched to them by name or short name, in date order, then
+    // the league's own results of every season in order.
+    ListMeetings(team1, team2 models.Team) ([]models.Meeting, error)
+    // SaveHistory stores imported results, skipping those already stored,
+    // and returns how many were new.
+    SaveHistory(ms []models.HistoricalMatch) (int, error)
+    // ListHistory returns imported results in date order, only those of
+    // the named team when team is not empty.
+    ListHistory(team string) ([]models.HistoricalMatch, error)
+
+    // ListDivisions returns the league pyramid ordered by level.
+    ListDivisions() ([]models.Division, error)
+    // ReplaceDivisions swaps the whole pyramid and its memberships.
+    ReplaceDivisions(divs []models.Division) error
+
+    // CurrentSeason returns the id of the season being played.
+    CurrentSeason() (int, error)
+    // GetGeneratorConfig returns the season's scoring environment, or the
+    // default one when nothing has been stored for it.
+    GetGeneratorConfig(season int) (models.GeneratorConfig, error)
+    // SaveGeneratorConfig stores cfg for cfg.Season, replacing any previous one.
+    SaveGeneratorConfig(cfg models.GeneratorConfig) error
+
+    // CreateSeason adds a new, non-current season and returns its id.
+    CreateSeason(name string) (int, error)
+    // SetCurrentSeason makes season the one being played.
+    SetCurrentSeason(season int) error
+
+    // SetSeasonTeams makes teams (with their ids) the current season's
+    // teams, with their strengths and ratings kept for this season only.
+    // Listed teams missing from the shared list are added back; teams
+    // other seasons use are never changed or removed.
+    SetSeasonTeams(teams []models.Team) error
+
+    // SaveSnapshot stores snap with its teams, matches and adjustments
+    // and returns it with ID and CreatedAt filled in.
+    SaveSnapshot(snap models.Snapshot) (models.Snapshot, error)
+    // ListSnapshots returns every snapshot without teams, matches or
+    // adjustments.
+    ListSnapshots() ([]models.Snapshot, error)
+    // GetSnapshot returns a full snapshot, or ErrNotFound.
+    GetSnapshot(id int) (models.Snapshot, error)
+
+    // WithActor returns a Repository that records actor as the author of
+    // every match change in the audit trail.
+    WithActor(actor string) Repository
+    // GetMatchHistory returns the audit trail of one match, oldest first.
+    GetMatchHistory(matchID int) ([]models.MatchEvent, error)
+    // GetMatchesAt rebuilds the current season's results as they stood at
+    // the given moment from the audit trail.
+    GetMatchesAt(at time.Time) ([]models.MatchResult, error)
+
+    // CreateJob queues a new job and returns it with ID, Status and
+    // CreatedAt filled in.
+    CreateJob(j models.Job) (models.Job, error)
+    // GetJob returns one job with its result, or ErrNotFound.
+    GetJob(id int) (models.Job, error)
+    // ListJobs returns jobs newest first without their results, only
+    // those with the given status when status is not empty.
+    ListJobs(status string) ([]models.Job, error)
+    // ClaimJob marks the oldest queued job running for a new attempt and
+    // returns it, or ErrNotFound when none is queued. Concurrent claims
+    // never get the same job.
+    ClaimJob() (models.Job, error)
+    // UpdateJobProgress records the progress of a running job's attempt
+    // and refreshes its heartbeat. It reports false when the job is no
+    // longer running that attempt, e.g. because it was cancelled.
+    UpdateJobProgress(id, attempt int, progress float64) (bool, error)
+    // FinishJob stores the outcome of a running job's attempt. It does
+    // nothing when the job is no longer running that attempt.
+    FinishJob(id, attempt int, status string, result []byte, msg string) error
+    // CancelJob cancels a queued or running job. It reports false when
+    // the job is neither.
+    CancelJob(id int) (bool, error)
+    // RequeueStaleJobs queues again the running jobs whose heartbeat is
+    // older than before, failing those already tried maxAttempts times.
+    // It returns how many it queued.
+    RequeueStaleJobs(before time.Time, maxAttempts int) (int, error)
+
+    // RunInTx runs fn against a Repository bound to one transaction. It
+    // commits when fn returns nil and rolls back otherwise.
+    RunInTx(fn func(tx Repository) error) error
+    // LockSeason takes an exclusive lock on the season until the enclosing
+    // transaction ends. It must be called inside RunInTx and fails with
+    // ErrLocked when another transaction already holds the lock.
+    LockSeason(season int) error
 }
@@ -7,4 +7,7 @@ type TableUpdater interface {
     // Update takes the current table and a match result,
     // and returns the new updated table.
     Update(current models.LeagueTable, result models.MatchResult) (models.LeagueTable, error)
+    // Adjust applies a points adjustment to the table. Walkovers reach
+    // the table as results and leave it unchanged.
+    Adjust(current models.LeagueTable, adj models.Adjustment) (models.LeagueTable, error)
 }
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Adjustment kinds.
+const (
+    AdjustmentPoints   = "points"   // points added or deducted
+    AdjustmentWalkover = "walkover" // a fixture awarded to one side
+)
+
+// WalkoverGoals is the score an awarded fixture is recorded with.
+const WalkoverGoals = 3
+
+// Adjustment is an administrative change to the table. A points
+// adjustment moves a team's points from matchday Week on; a walkover
+// records the fixture in Week between HomeTeamID and AwayTeamID as a
+// 3-0 win for TeamID.
+type Adjustment struct {
+    ID         int       `json:"id"`
+    Kind       string    `json:"kind"`
+    TeamID     int       `json:"team_id"`                // team whose points change; a walkover's winner
+    Points     int       `json:"points,omitempty"`       // negative for a deduction
+    Week       int       `json:"week"`                   // 0 = from the start of the season
+    HomeTeamID int       `json:"home_team_id,omitempty"` // walkover fixture
+    AwayTeamID int       `json:"away_team_id,omitempty"`
+    Reason     string    `json:"reason"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+// Result returns the MatchResult a walkover is recorded as.
+func (a Adjustment) Result() MatchResult {
+    m := MatchResult{Week: a.Week, HomeTeamID: a.HomeTeamID, AwayTeamID: a.AwayTeamID}
+    if a.TeamID == a.HomeTeamID {
+        m.HomeGoals = WalkoverGoals
+    } else {
+        m.AwayGoals = WalkoverGoals
+    }
+    return m
+}
@@ -0,0 +1,41 @@
+package models
+
+// BacktestScore rates how well a model's forecasts of match outcomes
+// (home win, draw, away win) matched the results.
+type BacktestScore struct {
+    Matches  int     `json:"matches"`
+    Brier    float64 `json:"brier"`    // mean Brier score over the three outcomes; 0 is perfect, 2 the worst
+    LogLoss  float64 `json:"log_loss"` // mean negative log of the chance given to what happened
+    Accuracy float64 `json:"accuracy"` // share of matches whose likeliest outcome happened
+}
+
+// BacktestWeek is the score of one matchday.
+type BacktestWeek struct {
+    Week int `json:"week"`
+    BacktestScore
+}
+
+// Backtest is a generator's score over a season's played matches, in all
+// and by matchday.
+type Backtest struct {
+    Generator string `json:"generator"`
+    BacktestScore
+    Weeks []BacktestWeek `json:"weeks"`
+}
+
+// SeasonBatchTeam is where a team finished over a batch of seasons.
+type SeasonBatchTeam struct {
+    TeamID      int       `json:"team_id"`
+    TeamName    string    `json:"team_name"`
+    AvgPoints   float64   `json:"avg_points"`
+    AvgPosition float64   `json:"avg_position"`
+    Positions   []float64 `json:"positions"` // Positions[i]: share of seasons finished in place i+1
+}
+
+// SeasonBatch is the rest of a season played many times over, its teams
+// ordered by average finishing position.
+type SeasonBatch struct {
+    Generator string            `json:"generator"`
+    Seasons   int               `json:"seasons"`
+    Teams     []SeasonBatchTeam `json:"teams"`
+}
@@ -0,0 +1,47 @@
+package models
+
+// Defaults filled in by SeasonDates.WithDefaults.
+const (
+    DefaultDaysBetween    = 7
+    DefaultKickoff        = "15:00"
+    DefaultMidweekOffset  = 3
+    DefaultMidweekKickoff = "19:45"
+    DefaultTimezone       = "UTC"
+)
+
+// SeasonDates places a season's matchdays on the calendar. Matchday 1 is
+// played on StartDate and every weekend matchday DaysBetween days after
+// the previous one; a midweek matchday falls MidweekOffset days after the
+// matchday before it without moving the weekend rhythm.
+type SeasonDates struct {
+    StartDate      string `json:"start_date"`              // YYYY-MM-DD of matchday 1
+    DaysBetween    int    `json:"days_between"`            // days between weekend matchdays
+    Kickoff        string `json:"kickoff"`                 // HH:MM local time of weekend games
+    MidweekWeeks   []int  `json:"midweek_weeks,omitempty"` // matchdays played midweek
+    MidweekOffset  int    `json:"midweek_offset"`          // days after the matchday before
+    MidweekKickoff string `json:"midweek_kickoff"`         // HH:MM local time of midweek games
+    Timezone       string `json:"timezone"`                // IANA name, e.g. Europe/London
+}
+
+// Enabled reports whether the season has dates.
+func (d SeasonDates) Enabled() bool { return d.StartDate != "" }
+
+// WithDefaults returns d with every unset field given its default.
+func (d SeasonDates) WithDefaults() SeasonDates {
+    if d.DaysBetween == 0 {
+        d.DaysBetween = DefaultDaysBetween
+    }
+    if d.Kickoff == "" {
+        d.Kickoff = DefaultKickoff
+    }
+    if d.MidweekOffset == 0 {
+        d.MidweekOffset = DefaultMidweekOffset
+    }
+    if d.MidweekKickoff == "" {
+        d.MidweekKickoff = DefaultMidweekKickoff
+    }
+    if d.Timezone == "" {
+        d.Timezone = DefaultTimezone
+    }
+    return d
+}
@@ -0,0 +1,15 @@
+package models
+
+// Division is one level of a league pyramid. Level 1 is the top flight.
+// The top Promotion teams go up automatically and the bottom Relegation
+// teams go down; the Playoff teams below the automatic places play a cup
+// whose winner is promoted too.
+type Division struct {
+    ID         int    `json:"id"`
+    Name       string `json:"name"`
+    Level      int    `json:"level"`
+    Promotion  int    `json:"promotion"`
+    Relegation int    `json:"relegation"`
+    Playoff    int    `json:"playoff"`
+    TeamIDs    []int  `json:"team_ids"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Match event actions.
+const (
+    EventInsert = "insert"
+    EventUpdate = "update"
+    EventDelete = "delete"
+    EventReset  = "reset" // every match was wiped
+)
+
+// MatchEvent is one entry of the append-only match audit trail. Old goals
+// are nil for inserts, new goals are nil for deletes.
+type MatchEvent struct {
+    ID           int64     `json:"id"`
+    MatchID      int       `json:"match_id,omitempty"`
+    Season       int       `json:"season"`
+    Action       string    `json:"action"`
+    Week         int       `json:"week,omitempty"`
+    HomeTeamID   int       `json:"home_team_id,omitempty"`
+    AwayTeamID   int       `json:"away_team_id,omitempty"`
+    OldHomeGoals *int      `json:"old_home_goals,omitempty"`
+    OldAwayGoals *int      `json:"old_away_goals,omitempty"`
+    NewHomeGoals *int      `json:"new_home_goals,omitempty"`
+    NewAwayGoals *int      `json:"new_away_goals,omitempty"`
+    Actor        string    `json:"actor"`
+    CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+)
+
+// Strength-to-lambda mappings understood by GeneratorConfig.
+const (
+    // MappingShare splits the side's goals by share of combined strength.
+    MappingShare = "share"
+    // MappingLinear scales the side's goals by strength / StrengthScale.
+    MappingLinear = "linear"
+    // MappingExp scales the side's goals by exp(strength diff / StrengthScale).
+    MappingExp = "exp"
+)
+
+// GeneratorConfig is the scoring environment of a season: how many goals
+// a game produces, how much the home side gets, and how team strengths
+// turn into expected goals (λ).
+type GeneratorConfig struct {
+    Season        int     `json:"season"`
+    AvgGoals      float64 `json:"avg_goals"`      // mean total goals between two equal teams
+    HomeAdvantage float64 `json:"home_advantage"` // home goals / away goals between two equal teams
+    Dispersion    float64 `json:"dispersion"`     // 0 = Poisson, >0 = negative binomial (var = μ + d·μ²)
+    Mapping       string  `json:"mapping"`        // share | linear | exp
+    StrengthScale float64 `json:"strength_scale"` // strength units per unit of λ (linear, exp)
+}
+
+// DefaultGeneratorConfig reproduces the original 3.0 / 2.5 Poisson split.
+func DefaultGeneratorConfig() GeneratorConfig {
+    return GeneratorConfig{
+        AvgGoals:      2.75,
+        HomeAdvantage: 1.2,
+        Dispersion:    0,
+        Mapping:       MappingShare,
+        StrengthScale: 75,
+    }
+}
+
+// Validate reports the first out-of-range field.
+func (c GeneratorConfig) Validate() error {
+    switch {
+    case c.AvgGoals <= 0:
+        return fmt.Errorf("avg_goals must be > 0")
+    case c.HomeAdvantage <= 0:
+        return fmt.Errorf("home_advantage must be > 0")
+    case c.Dispersion < 0:
+        return fmt.Errorf("dispersion must be >= 0")
+    case c.StrengthScale <= 0:
+        return fmt.Errorf("strength_scale must be > 0")
+    }
+    switch c.Mapping {
+    case MappingShare, MappingLinear, MappingExp:
+        return nil
+    }
+    return fmt.Errorf("unknown mapping %q", c.Mapping)
+}
+
+// Lambdas returns the expected home and away goals for the given strengths.
+func (c GeneratorConfig) Lambdas(homeStrength, awayStrength int) (float64, float64) {
+    hs, as := float64(homeStrength), float64(awayStrength)
+    homeBase := c.AvgGoals * c.HomeAdvantage / (1 + c.HomeAdvantage)
+    awayBase := c.AvgGoals / (1 + c.HomeAdvantage)
+
+    switch c.Mapping {
+    case MappingLinear:
+        return homeBase * hs / c.StrengthScale, awayBase * as / c.StrengthScale
+    case MappingExp:
+        return homeBase * math.Exp((hs-as)/c.StrengthScale), awayBase * math.Exp((as-hs)/c.StrengthScale)
+    }
+    total := hs + as
+    if total <= 0 {
+        return homeBase, awayBase
+    }
+    return 2 * homeBase * hs / total, 2 * awayBase * as / total
+}
+
+// SampleGoals draws a goal count with mean lambda, using a Poisson when
+// Dispersion is zero and a gamma-Poisson (negative binomial) mixture otherwise.
+func (c GeneratorConfig) SampleGoals(lambda float64, rng *rand.Rand) int {
+    if lambda <= 0 {
+        return 0
+    }
+    if c.Dispersion > 0 {
+        shape := 1 / c.Dispersion
+        lambda = sampleGamma(shape, rng) * lambda / shape
+    }
+    L := math.Exp(-lambda)
+    k, p := 0, 1.0
+    for p > L {
+        k++
+        p *= rng.Float64()
+    }
+    return k - 1
+}
+
+// sampleGamma draws Gamma(shape, 1) using Marsaglia and Tsang's method.
+func sampleGamma(shape float64, rng *rand.Rand) float64 {
+    if shape < 1 {
+        return sampleGamma(shape+1, rng) * math.Pow(rng.Float64(), 1/shape)
+    }
+    d := shape - 1.0/3.0
+    c := 1 / math.Sqrt(9*d)
+    for {
+        x := rng.NormFloat64()
+        v := 1 + c*x
+        if v <= 0 {
+            continue
+        }
+        v = v * v * v
+        u := rng.Float64()
+        if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+            return d * v
+        }
+    }
+}
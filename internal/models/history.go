@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// MeetingSourceLeague is the Source of meetings played in the league
+// itself rather than imported.
+const MeetingSourceLeague = "league"
+
+// HistoricalMatch is a result imported from outside the league, such as a
+// past season of a real competition. Its teams are known by name only.
+type HistoricalMatch struct {
+    ID          int         `json:"id,omitempty"`
+    Source      string      `json:"source"`      // where it was imported from, e.g. football-data
+    Competition string      `json:"competition"` // e.g. PL
+    Season      string      `json:"season"`      // e.g. 2023
+    PlayedOn    time.Time   `json:"played_on,omitzero"`
+    HomeTeam    string      `json:"home_team"`
+    AwayTeam    string      `json:"away_team"`
+    HomeGoals   int         `json:"home_goals"`
+    AwayGoals   int         `json:"away_goals"`
+    Stats       *MatchStats `json:"stats,omitempty"`
+}
+
+// Meeting is one game between two teams, from any of the league's seasons
+// or from imported history.
+type Meeting struct {
+    Source      string    `json:"source"` // MeetingSourceLeague or the import's source
+    Competition string    `json:"competition,omitempty"`
+    Season      string    `json:"season"`
+    Week        int       `json:"week,omitempty"`
+    PlayedOn    time.Time `json:"played_on,omitzero"`
+    HomeTeamID  int       `json:"home_team_id"`
+    AwayTeamID  int       `json:"away_team_id"`
+    HomeGoals   int       `json:"home_goals"`
+    AwayGoals   int       `json:"away_goals"`
+}
+
+// HeadToHead is the record of two teams against each other, counted from
+// Team1's side.
+type HeadToHead struct {
+    Team1ID    int       `json:"team1_id"`
+    Team1Name  string    `json:"team1_name"`
+    Team2ID    int       `json:"team2_id"`
+    Team2Name  string    `json:"team2_name"`
+    Played     int       `json:"played"`
+    Team1Wins  int       `json:"team1_wins"`
+    Draws      int       `json:"draws"`
+    Team2Wins  int       `json:"team2_wins"`
+    Team1Goals int       `json:"team1_goals"`
+    Team2Goals int       `json:"team2_goals"`
+    Meetings   []Meeting `json:"meetings"` // imported history first, then the league's seasons in order
+}
+
+// ResultsGrid is the current season's results grid: Results[i][j] holds
+// the games Teams[i] played at home against Teams[j], in week order.
+type ResultsGrid struct {
+    Teams   []Team            `json:"teams"`
+    Results [][][]MatchResult `json:"results"`
+}
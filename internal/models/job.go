@@ -0,0 +1,45 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// Job kinds.
+const (
+    JobPredict     = "predict"
+    JobBacktest    = "backtest"
+    JobSeasonBatch = "season_batch"
+)
+
+// Job statuses. Queued and running jobs are still to finish; the others
+// are final.
+const (
+    JobQueued    = "queued"
+    JobRunning   = "running"
+    JobSucceeded = "succeeded"
+    JobFailed    = "failed"
+    JobCancelled = "cancelled"
+)
+
+// Job is a heavy simulation run in the background. Params and Result are
+// JSON whose shape depends on Kind.
+type Job struct {
+    ID         int             `json:"id"`
+    Kind       string          `json:"kind"`
+    Status     string          `json:"status"`
+    Params     json.RawMessage `json:"params"`
+    Progress   float64         `json:"progress"` // 0-1
+    Result     json.RawMessage `json:"result,omitempty"`
+    Error      string          `json:"error,omitempty"`
+    Attempts   int             `json:"attempts"` // times a worker has picked it up
+    CreatedBy  string          `json:"created_by"`
+    CreatedAt  time.Time       `json:"created_at"`
+    StartedAt  time.Time       `json:"started_at,omitzero"`
+    FinishedAt time.Time       `json:"finished_at,omitzero"`
+}
+
+// Finished reports whether j has reached a final status.
+func (j Job) Finished() bool {
+    return j.Status != JobQueued && j.Status != JobRunning
+}
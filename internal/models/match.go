@@ -1,10 +1,63 @@
 package models
 
+import "time"
+
 // MatchResult holds the outcome of a single fixture.
 type MatchResult struct {
+    ID         int `json:"id,omitempty"`
     Week       int `json:"week"`
     HomeTeamID int `json:"home_team_id"`
     AwayTeamID int `json:"away_team_id"`
     HomeGoals  int `json:"home_goals"`
     AwayGoals  int `json:"away_goals"`
+    // Kickoff is when the fixture was played; zero without season dates.
+    Kickoff time.Time `json:"kickoff,omitzero"`
+    // Stats and Goals are optional details of how the game went.
+    Stats *MatchStats `json:"stats,omitempty"`
+    Goals []Goal      `json:"goals,omitempty"` // in the order they were scored
+}
+
+// MatchStats are the optional statistics of a game, each for the home and
+// the away side.
+type MatchStats struct {
+    HomeXG            float64 `json:"home_xg"` // expected goals; both 0 when unknown
+    AwayXG            float64 `json:"away_xg"`
+    HomeShots         int     `json:"home_shots"`
+    AwayShots         int     `json:"away_shots"`
+    HomeShotsOnTarget int     `json:"home_shots_on_target"`
+    AwayShotsOnTarget int     `json:"away_shots_on_target"`
+    HomePossession    int     `json:"home_possession"` // percent; both 0 when unknown
+    AwayPossession    int     `json:"away_possession"`
+    HomeCorners       int     `json:"home_corners"`
+    AwayCorners       int     `json:"away_corners"`
+    HomeYellowCards   int     `json:"home_yellow_cards"`
+    AwayYellowCards   int     `json:"away_yellow_cards"`
+    HomeRedCards      int     `json:"home_red_cards"`
+    AwayRedCards      int     `json:"away_red_cards"`
+}
+
+// HasXG reports whether s holds expected goals.
+func (s *MatchStats) HasXG() bool {
+    return s != nil && (s.HomeXG > 0 || s.AwayXG > 0)
+}
+
+// Goal is one goal of a game. TeamID is the side it counts for, which
+// for an own goal is the opponent of the scorer.
+type Goal struct {
+    Minute    int    `json:"minute"`               // 1-120
+    AddedTime int    `json:"added_time,omitempty"` // minutes into stoppage time, e.g. 3 for 90+3
+    TeamID    int    `json:"team_id"`
+    Scorer    string `json:"scorer,omitempty"`
+    Penalty   bool   `json:"penalty,omitempty"`
+    OwnGoal   bool   `json:"own_goal,omitempty"`
+}
+
+// WeekResult holds every fixture played on one matchday.
+type WeekResult struct {
+    Week     int           `json:"week"`
+    Fixtures []MatchResult `json:"fixtures"`
+    Byes     []int         `json:"byes,omitempty"` // teams without a game this week
+    // Timelines are the minute-by-minute accounts of the fixtures, when
+    // the generator plays matches that way.
+    Timelines []MatchTimeline `json:"timelines,omitempty"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Snapshot is a named copy of a season's teams, results (with their
+// kickoffs, stats and goals) and adjustments.
+type Snapshot struct {
+    ID          int           `json:"id"`
+    Name        string        `json:"name"`
+    Season      int           `json:"season"`
+    CreatedAt   time.Time     `json:"created_at"`
+    Teams       []Team        `json:"teams,omitempty"`
+    Matches     []MatchResult `json:"matches,omitempty"`
+    Adjustments []Adjustment  `json:"adjustments,omitempty"`
+}
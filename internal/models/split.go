@@ -0,0 +1,27 @@
+package models
+
+// SplitConfig is a split-season format, as in the Scottish Premiership or
+// the Belgian Pro League: once the fixtures up to AfterWeek are played the
+// table is cut into groups, top group first, and each group plays Legs
+// more rounds among itself. A team finishes within its group, so the
+// bottom group can never overtake the top one. A config without groups
+// means the season does not split.
+type SplitConfig struct {
+    AfterWeek   int          `json:"after_week"`   // last matchday of the regular phase
+    Groups      []SplitGroup `json:"groups"`       // top of the table first
+    Legs        int          `json:"legs"`         // meetings per pair within a group: 1 or 2
+    HalvePoints bool         `json:"halve_points"` // points are halved, rounding up, at the split
+}
+
+// SplitGroup is one group of a split season.
+type SplitGroup struct {
+    Name    string `json:"name"`
+    Size    int    `json:"size"`
+    TeamIDs []int  `json:"team_ids,omitempty"` // filled in once the season has split
+}
+
+// Enabled reports whether the season splits at all.
+func (c SplitConfig) Enabled() bool { return len(c.Groups) > 0 }
+
+// Done reports whether the table has already been split into groups.
+func (c SplitConfig) Done() bool { return c.Enabled() && len(c.Groups[0].TeamIDs) > 0 }
@@ -0,0 +1,68 @@
+package models
+
+// Record totals a set of games from one team's point of view.
+type Record struct {
+    Played       int `json:"played"`
+    Won          int `json:"won"`
+    Drawn        int `json:"drawn"`
+    Lost         int `json:"lost"`
+    GoalsFor     int `json:"goals_for"`
+    GoalsAgainst int `json:"goals_against"`
+    Points       int `json:"points"`
+    CleanSheets  int `json:"clean_sheets"`
+}
+
+// Streak is a run of games sharing an outcome: the one still going and
+// the longest of the season.
+type Streak struct {
+    Current int `json:"current"`
+    Longest int `json:"longest"`
+}
+
+// Streaks are a team's runs of wins, games without defeat, games without
+// a win, games without scoring and clean sheets.
+type Streaks struct {
+    Win        Streak `json:"win"`
+    Unbeaten   Streak `json:"unbeaten"`
+    Winless    Streak `json:"winless"`
+    Scoreless  Streak `json:"scoreless"`
+    CleanSheet Streak `json:"clean_sheet"`
+}
+
+// StatsWeek is where a team stood after one matchday.
+type StatsWeek struct {
+    Week     int     `json:"week"`
+    Played   int     `json:"played"`
+    Points   int     `json:"points"`   // as in the table, adjustments included
+    PPG      float64 `json:"ppg"`      // points won on the pitch per game so far
+    Position int     `json:"position"` // 1 = top of the table
+}
+
+// TeamStats is a team's form and trends over the current season.
+type TeamStats struct {
+    TeamID        int         `json:"team_id"`
+    TeamName      string      `json:"team_name"`
+    Form          string      `json:"form"` // results of the last games, oldest first: W, D or L
+    FormPoints    int         `json:"form_points"`
+    PPG           float64     `json:"ppg"`
+    CleanSheets   int         `json:"clean_sheets"`
+    FailedToScore int         `json:"failed_to_score"`
+    Home          Record      `json:"home"`
+    Away          Record      `json:"away"`
+    Streaks       Streaks     `json:"streaks"`
+    Timeline      []StatsWeek `json:"timeline,omitempty"` // per matchday; only for a single team
+}
+
+// LeagueStats sums up the current season across all teams.
+type LeagueStats struct {
+    Played         int          `json:"played"`
+    Goals          int          `json:"goals"`
+    GoalsPerGame   float64      `json:"goals_per_game"`
+    HomeWins       int          `json:"home_wins"`
+    Draws          int          `json:"draws"`
+    AwayWins       int          `json:"away_wins"`
+    CleanSheets    int          `json:"clean_sheets"`
+    BiggestWin     *MatchResult `json:"biggest_win,omitempty"`
+    HighestScoring *MatchResult `json:"highest_scoring,omitempty"`
+    Teams          []TeamStats  `json:"teams"` // in table order
+}
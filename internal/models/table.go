@@ -1,5 +1,7 @@
 package models
 
+import "sort"
+
 // TeamStanding tracks a single team's cumulative stats.
 type TeamStanding struct {
     TeamID       int    `json:"team_id"`
@@ -12,7 +14,25 @@ type TeamStanding struct {
     GoalsAgainst int    `json:"goals_against"`
     GoalDiff     int    `json:"goal_diff"`
     Points       int    `json:"points"`
+    Byes         int    `json:"byes,omitempty"`       // matchdays sat out so far
+    Postponed    int    `json:"postponed,omitempty"`  // games in hand: fixtures due so far but moved later
+    Adjustment   int    `json:"adjustment,omitempty"` // points added or deducted by adjustments
+    Group        string `json:"group,omitempty"`      // group of a split season, once split
 }
 
 // LeagueTable is the full standings for all teams.
 type LeagueTable []TeamStanding
+
+// Sort orders the table by points, then goal difference, then goals scored.
+func (t LeagueTable) Sort() {
+    sort.SliceStable(t, func(i, j int) bool {
+        a, b := t[i], t[j]
+        if a.Points != b.Points {
+            return a.Points > b.Points
+        }
+        if a.GoalDiff != b.GoalDiff {
+            return a.GoalDiff > b.GoalDiff
+        }
+        return a.GoalsFor > b.GoalsFor
+    })
+}
@@ -2,7 +2,11 @@ package models
 
 // Team represents a club in the mini-league.
 type Team struct {
-    ID       int    `json:"id"`
-    Name     string `json:"name"`
-    Strength int    `json:"strength"` // higher = stronger
+    ID        int     `json:"id"`
+    Name      string  `json:"name"`
+    ShortName string  `json:"short_name,omitempty"`
+    Strength  int     `json:"strength"`         // higher = stronger
+    Rating    float64 `json:"rating,omitempty"` // Elo rating; 0 = unrated
+    Color     string  `json:"color,omitempty"`  // primary colour, #rrggbb
+    CrestURL  string  `json:"crest_url,omitempty"`
 }
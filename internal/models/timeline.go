@@ -0,0 +1,27 @@
+package models
+
+// Timeline event kinds.
+const (
+    TimelineGoal       = "goal"
+    TimelineYellowCard = "yellow_card"
+    TimelineRedCard    = "red_card"
+    TimelineHalfTime   = "half_time"
+    TimelineFullTime   = "full_time"
+)
+
+// TimelineEvent is one moment of a match played minute by minute.
+type TimelineEvent struct {
+    Minute    int    `json:"minute"`               // 1-90; stoppage time stays on 45 or 90
+    AddedTime int    `json:"added_time,omitempty"` // minutes into stoppage time
+    Kind      string `json:"kind"`
+    TeamID    int    `json:"team_id,omitempty"` // side a goal counts for, or a card is shown to
+    HomeGoals int    `json:"home_goals"`        // score after the event
+    AwayGoals int    `json:"away_goals"`
+}
+
+// MatchTimeline is everything that happened in one match, in order.
+type MatchTimeline struct {
+    HomeTeamID int             `json:"home_team_id"`
+    AwayTeamID int             `json:"away_team_id"`
+    Events     []TimelineEvent `json:"events"`
+}
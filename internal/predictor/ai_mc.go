@@ -10,15 +10,17 @@ import (
 )
 
 // AIMC uses a simple neural network for match outcome prediction.
-type AIMC struct{}
+type AIMC struct {
+	rng *rand.Rand
+}
 
 func NewAIMonteCarlo() Predictor {
-	return &AIMC{}
+	return &AIMC{rng: newRNG()}
 }
 
 // MLP Neural Net Monte Carlo (renamed from AI)
 func NewMLPNeuralNetMonteCarlo() Predictor {
-	return &AIMC{}
+	return &AIMC{rng: newRNG()}
 }
 
 func (a *AIMC) Name() string { return "AI Monte Carlo" }
@@ -81,18 +83,7 @@ func (a *AIMC) Predict(
 		copy(simTable, table)
 
 		for _, m := range remaining {
-			home := teamMap[m.HomeTeamID]
-			away := teamMap[m.AwayTeamID]
-			ph, pd, _ := predictOutcome(float64(home.Strength), float64(away.Strength))
-			r := rng.Float64()
-			var hg, ag int
-			if r < ph {
-				hg, ag = 1, 0 // home win
-			} else if r < ph+pd {
-				hg, ag = 1, 1 // draw
-			} else {
-				hg, ag = 0, 1 // away win
-			}
+			hg, ag := sampleOutcome(teamMap[m.HomeTeamID], teamMap[m.AwayTeamID], rng)
 			applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
 		}
 		champ := findChampion(simTable)
@@ -104,3 +95,21 @@ func (a *AIMC) Predict(
 	}
 	return probs, nil
 }
+
+// Generate implements interfaces.MatchGenerator.
+func (a *AIMC) Generate(home, away models.Team) (models.MatchResult, error) {
+	hg, ag := sampleOutcome(home, away, a.rng)
+	return newResult(home, away, hg, ag), nil
+}
+
+// sampleOutcome draws a 1-0, 1-1 or 0-1 result from the MLP's probabilities.
+func sampleOutcome(home, away models.Team, rng *rand.Rand) (int, int) {
+	ph, pd, _ := predictOutcome(float64(home.Strength), float64(away.Strength))
+	r := rng.Float64()
+	if r < ph {
+		return 1, 0 // home win
+	} else if r < ph+pd {
+		return 1, 1 // draw
+	}
+	return 0, 1 // away win
+}
@@ -9,11 +9,13 @@ import (
 )
 
 // btMC uses a Bradley–Terry simulation.
-type btMC struct{}
+type btMC struct {
+    rng *rand.Rand
+}
 
 // NewBradleyTerryMonteCarlo creates a BT predictor.
 func NewBradleyTerryMonteCarlo() Predictor {
-    return &btMC{}
+    return &btMC{rng: newRNG()}
 }
 
 func (b *btMC) Name() string {
@@ -40,15 +42,8 @@ func (b *btMC) Predict(
         copy(simTable, table)
 
         for _, m := range remaining {
-            sa := strengths[m.HomeTeamID]
-            sb := strengths[m.AwayTeamID]
-            pa := sa / (sa + sb)
-
-            if rng.Float64() < pa {
-                applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, 1, 0)
-            } else {
-                applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, 0, 1)
-            }
+            hg, ag := sampleBT(strengths[m.HomeTeamID], strengths[m.AwayTeamID], rng)
+            applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
         }
 
         champ := findChampion(simTable)
@@ -61,3 +56,18 @@ func (b *btMC) Predict(
     }
     return probs, nil
 }
+
+// Generate implements interfaces.MatchGenerator.
+func (b *btMC) Generate(home, away models.Team) (models.MatchResult, error) {
+    hg, ag := sampleBT(float64(home.Strength), float64(away.Strength), b.rng)
+    return newResult(home, away, hg, ag), nil
+}
+
+// sampleBT draws a 1-0 or 0-1 result with P(home) = sa / (sa + sb).
+func sampleBT(sa, sb float64, rng *rand.Rand) (int, int) {
+    pa := sa / (sa + sb)
+    if rng.Float64() < pa {
+        return 1, 0
+    }
+    return 0, 1
+}
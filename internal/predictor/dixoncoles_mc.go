@@ -2,6 +2,8 @@ package predictor
 
 import (
     "errors"
+    "math/rand"
+    "time"
 
     "github.com/musta/insider-league/internal/interfaces"
     "github.com/musta/insider-league/internal/models"
@@ -27,3 +29,105 @@ func (m *MonteCarlo) Predict(
 ) (map[int]float64, error) {
     return nil, errors.New("Monte Carlo model not implemented")
 }
+
+// dcRho is the Dixon-Coles low-score dependence parameter.
+const dcRho = -0.13
+
+// dcMaxGoals caps the score grid sampled by the Dixon-Coles model.
+const dcMaxGoals = 10
+
+// DixonColesMC is a Poisson model with the Dixon-Coles correction for
+// 0-0, 1-0, 0-1 and 1-1 scorelines.
+type DixonColesMC struct {
+    rng *rand.Rand
+    cfg models.GeneratorConfig
+}
+
+// NewDixonColesMonteCarlo creates a Dixon-Coles predictor.
+func NewDixonColesMonteCarlo() Predictor {
+    return &DixonColesMC{rng: newRNG(), cfg: models.DefaultGeneratorConfig()}
+}
+
+func (d *DixonColesMC) Name() string { return "Dixon-Coles Monte Carlo" }
+
+// SetConfig implements Configurable.
+func (d *DixonColesMC) SetConfig(cfg models.GeneratorConfig) { d.cfg = cfg }
+
+func (d *DixonColesMC) Predict(
+    teams []models.Team,
+    table models.LeagueTable,
+    remaining []interfaces.Matchup,
+    sims int,
+) (map[int]float64, error) {
+    wins := make(map[int]int)
+    teamMap := make(map[int]models.Team)
+    for _, t := range teams {
+        teamMap[t.ID] = t
+    }
+    rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+    for i := 0; i < sims; i++ {
+        simTable := make(models.LeagueTable, len(table))
+        copy(simTable, table)
+
+        for _, m := range remaining {
+            hg, ag := sampleDixonColes(d.cfg, teamMap[m.HomeTeamID], teamMap[m.AwayTeamID], rng)
+            applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
+        }
+
+        champ := findChampion(simTable)
+        wins[champ]++
+    }
+
+    probs := make(map[int]float64)
+    for id, cnt := range wins {
+        probs[id] = float64(cnt) / float64(sims)
+    }
+    return probs, nil
+}
+
+// Generate implements interfaces.MatchGenerator.
+func (d *DixonColesMC) Generate(home, away models.Team) (models.MatchResult, error) {
+    hg, ag := sampleDixonColes(d.cfg, home, away, d.rng)
+    return newResult(home, away, hg, ag), nil
+}
+
+// sampleDixonColes draws a scoreline from the tau-adjusted Poisson grid.
+func sampleDixonColes(cfg models.GeneratorConfig, home, away models.Team, rng *rand.Rand) (int, int) {
+    hl, al := cfg.Lambdas(home.Strength, away.Strength)
+
+    var grid [dcMaxGoals + 1][dcMaxGoals + 1]float64
+    sum := 0.0
+    for h := 0; h <= dcMaxGoals; h++ {
+        for a := 0; a <= dcMaxGoals; a++ {
+            p := poissonPMF(h, hl) * poissonPMF(a, al) * dcTau(h, a, hl, al)
+            grid[h][a] = p
+            sum += p
+        }
+    }
+    r := rng.Float64() * sum
+    for h := 0; h <= dcMaxGoals; h++ {
+        for a := 0; a <= dcMaxGoals; a++ {
+            r -= grid[h][a]
+            if r <= 0 {
+                return h, a
+            }
+        }
+    }
+    return 0, 0
+}
+
+// dcTau is the Dixon-Coles adjustment factor for low scores.
+func dcTau(h, a int, hl, al float64) float64 {
+    switch {
+    case h == 0 && a == 0:
+        return 1 - hl*al*dcRho
+    case h == 0 && a == 1:
+        return 1 + hl*dcRho
+    case h == 1 && a == 0:
+        return 1 + al*dcRho
+    case h == 1 && a == 1:
+        return 1 - dcRho
+    }
+    return 1
+}
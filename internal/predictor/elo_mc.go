@@ -3,6 +3,7 @@ package predictor
 import (
     "math"
     "math/rand"
+    "sync"
     "time"
 
     "github.com/musta/insider-league/internal/interfaces"
@@ -15,11 +16,15 @@ const (
 )
 
 // EloMC uses Elo rating simulations.
-type EloMC struct{}
+type EloMC struct {
+    mu      sync.Mutex
+    rng     *rand.Rand
+    ratings map[int]float64 // carried across Generate calls
+}
 
 // NewEloMonteCarlo creates an Elo predictor.
 func NewEloMonteCarlo() Predictor {
-    return &EloMC{}
+    return &EloMC{rng: newRNG(), ratings: make(map[int]float64)}
 }
 
 func (e *EloMC) Name() string { return "Elo Monte Carlo" }
@@ -36,30 +41,14 @@ func (e *EloMC) Predict(
         // initialize ratings
         ratings := make(map[int]float64)
         for _, t := range teams {
-            ratings[t.ID] = BaseRating
+            ratings[t.ID] = StartRating(t)
         }
         simTable := make(models.LeagueTable, len(table))
         copy(simTable, table)
         rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
         for _, m := range remaining {
-            Ra := ratings[m.HomeTeamID]
-            Rb := ratings[m.AwayTeamID]
-            Ea := 1.0 / (1.0 + math.Pow(10, (Rb-Ra)/400))
-            Eb := 1.0 - Ea
-
-            // simulate win/loss (no draws here for simplicity)
-            var hg, ag int
-            if rng.Float64() < Ea {
-                hg, ag = 1, 0
-            } else {
-                hg, ag = 0, 1
-            }
-
-            // update ratings
-            ratings[m.HomeTeamID] += KFactor * (float64(hg) - Ea)
-            ratings[m.AwayTeamID] += KFactor * (float64(ag) - Eb)
-
+            hg, ag := sampleElo(ratings, m.HomeTeamID, m.AwayTeamID, rng)
             applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
         }
 
@@ -73,3 +62,71 @@ func (e *EloMC) Predict(
     }
     return probs, nil
 }
+
+// Generate implements interfaces.MatchGenerator. Ratings start at the
+// team's own rating (or BaseRating) and are updated after every generated
+// match, as in Predict.
+func (e *EloMC) Generate(home, away models.Team) (models.MatchResult, error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    for _, t := range []models.Team{home, away} {
+        if _, ok := e.ratings[t.ID]; !ok {
+            e.ratings[t.ID] = StartRating(t)
+        }
+    }
+    hg, ag := sampleElo(e.ratings, home.ID, away.ID, e.rng)
+    return newResult(home, away, hg, ag), nil
+}
+
+// Reset implements interfaces.StatefulGenerator: every team goes back to
+// its starting rating.
+func (e *EloMC) Reset() {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.ratings = make(map[int]float64)
+}
+
+// StartRating is the team's stored rating, or BaseRating when unrated.
+func StartRating(t models.Team) float64 {
+    if t.Rating > 0 {
+        return t.Rating
+    }
+    return BaseRating
+}
+
+// sampleElo draws a win/loss result from the Elo expectation and updates
+// both ratings in place.
+func sampleElo(ratings map[int]float64, homeID, awayID int, rng *rand.Rand) (int, int) {
+    Ea := eloExpectation(ratings[homeID], ratings[awayID])
+
+    // simulate win/loss (no draws here for simplicity)
+    var hg, ag int
+    if rng.Float64() < Ea {
+        hg, ag = 1, 0
+    } else {
+        hg, ag = 0, 1
+    }
+
+    UpdateElo(ratings, homeID, awayID, hg, ag)
+    return hg, ag
+}
+
+// UpdateElo moves both ratings towards a result (1 for a win, ½ for a
+// draw, 0 for a defeat), scaled by KFactor.
+func UpdateElo(ratings map[int]float64, homeID, awayID, homeGoals, awayGoals int) {
+    Ea := eloExpectation(ratings[homeID], ratings[awayID])
+    Sa := 0.5
+    switch {
+    case homeGoals > awayGoals:
+        Sa = 1
+    case homeGoals < awayGoals:
+        Sa = 0
+    }
+    ratings[homeID] += KFactor * (Sa - Ea)
+    ratings[awayID] -= KFactor * (Sa - Ea)
+}
+
+// eloExpectation is the expected score of a side rated Ra against Rb.
+func eloExpectation(Ra, Rb float64) float64 {
+    return 1.0 / (1.0 + math.Pow(10, (Rb-Ra)/400))
+}
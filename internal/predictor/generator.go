@@ -0,0 +1,37 @@
+package predictor
+
+import (
+    "fmt"
+
+    "github.com/musta/insider-league/internal/engine"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// NewGenerator returns the match model of the named predictor as an
+// interfaces.MatchGenerator. Names match the /predict model parameter.
+// The logistic model needs a live table and the rest model the fixture's
+// date, so neither has a generator.
+// Goal-based models draw their λ from cfg. "live" is no predictor but the
+// minute-by-minute match engine, which also returns each match's timeline.
+func NewGenerator(name string, cfg models.GeneratorConfig) (interfaces.MatchGenerator, error) {
+    switch name {
+    case "poisson":
+        return &PoissonMC{rng: newRNG(), cfg: cfg}, nil
+    case "elo":
+        return &EloMC{rng: newRNG(), ratings: make(map[int]float64)}, nil
+    case "bt":
+        return &btMC{rng: newRNG()}, nil
+    case "mlp":
+        return &AIMC{rng: newRNG()}, nil
+    case "bivariate":
+        return &BivariatePoissonMC{rng: newRNG(), cfg: cfg}, nil
+    case "zip":
+        return &ZeroInflatedPoissonMC{rng: newRNG(), cfg: cfg}, nil
+    case "dixoncoles":
+        return &DixonColesMC{rng: newRNG(), cfg: cfg}, nil
+    case "live":
+        return engine.New(cfg), nil
+    }
+    return nil, fmt.Errorf("unknown generator %q", name)
+}
@@ -3,6 +3,7 @@ package predictor
 import (
     "math"
     "math/rand"
+    "time"
 
     "github.com/musta/insider-league/internal/models"
 )
@@ -82,7 +83,30 @@ func samplePoisson(lambda float64, rng *rand.Rand) int {
     return k - 1
 }
 
-// computeLambda scales a team's strength into an expected-goals lambda.
-func computeLambda(strength, totalStrength, maxGoals float64) float64 {
-    return strength / totalStrength * maxGoals
+// poissonPMF returns P(X = k) for X ~ Poisson(lambda).
+func poissonPMF(k int, lambda float64) float64 {
+    if lambda <= 0 {
+        if k == 0 {
+            return 1
+        }
+        return 0
+    }
+    lg, _ := math.Lgamma(float64(k + 1))
+    return math.Exp(float64(k)*math.Log(lambda) - lambda - lg)
+}
+
+// newRNG returns a time-seeded source for predictors used as generators.
+func newRNG() *rand.Rand {
+    return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// newResult builds the MatchResult returned by a predictor's Generate.
+func newResult(home, away models.Team, homeGoals, awayGoals int) models.MatchResult {
+    return models.MatchResult{
+        Week:       0, // set by the caller when the fixture is scheduled
+        HomeTeamID: home.ID,
+        AwayTeamID: away.ID,
+        HomeGoals:  homeGoals,
+        AwayGoals:  awayGoals,
+    }
 }
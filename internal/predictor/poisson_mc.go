@@ -9,15 +9,21 @@ import (
 )
 
 // PoissonMC uses Poisson sampling for the rest of the season.
-type PoissonMC struct{}
+type PoissonMC struct {
+    rng *rand.Rand
+    cfg models.GeneratorConfig
+}
 
 // NewPoissonMonteCarlo creates a Poisson Monte Carlo predictor.
 func NewPoissonMonteCarlo() Predictor {
-    return &PoissonMC{}
+    return &PoissonMC{rng: newRNG(), cfg: models.DefaultGeneratorConfig()}
 }
 
 func (p *PoissonMC) Name() string { return "Poisson Monte Carlo" }
 
+// SetConfig implements Configurable.
+func (p *PoissonMC) SetConfig(cfg models.GeneratorConfig) { p.cfg = cfg }
+
 func (p *PoissonMC) Predict(
     teams []models.Team,
     table models.LeagueTable,
@@ -25,10 +31,6 @@ func (p *PoissonMC) Predict(
     sims int,
 ) (map[int]float64, error) {
     wins := make(map[int]int)
-    totalStr := 0.0
-    for _, t := range teams {
-        totalStr += float64(t.Strength)
-    }
 
     rng := rand.New(rand.NewSource(time.Now().UnixNano()))
     for i := 0; i < sims; i++ {
@@ -47,11 +49,10 @@ func (p *PoissonMC) Predict(
                 }
             }
             // compute lambdas
-            hl := computeLambda(float64(home.Strength), totalStr, 3.0)
-            al := computeLambda(float64(away.Strength), totalStr, 2.5)
+            hl, al := p.cfg.Lambdas(home.Strength, away.Strength)
             // sample goals
-            hg := samplePoisson(hl, rng)
-            ag := samplePoisson(al, rng)
+            hg := p.cfg.SampleGoals(hl, rng)
+            ag := p.cfg.SampleGoals(al, rng)
             // apply to table
             applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
         }
@@ -66,3 +67,9 @@ func (p *PoissonMC) Predict(
     }
     return probs, nil
 }
+
+// Generate implements interfaces.MatchGenerator.
+func (p *PoissonMC) Generate(home, away models.Team) (models.MatchResult, error) {
+    hl, al := p.cfg.Lambdas(home.Strength, away.Strength)
+    return newResult(home, away, p.cfg.SampleGoals(hl, p.rng), p.cfg.SampleGoals(al, p.rng)), nil
+}
@@ -1,6 +1,7 @@
 package predictor
 
 import (
+	"math"
 	"math/rand"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 // Predictor computes championship probabilities.
 type Predictor interface {
 	// Predict runs sims simulations and returns a map of teamID→probability.
+	// remaining lists fixtures, not matchdays: a team with a bye simply has
+	// no fixture that week. Models play the fixtures one by one and rate
+	// teams per game played, so none counts on every team playing every
+	// week; the champion is only picked once every fixture is played.
 	Predict(
 		teams []models.Team,
 		table models.LeagueTable,
@@ -22,15 +27,40 @@ type Predictor interface {
 	Name() string
 }
 
+// Configurable is implemented by goal-based models whose expected goals
+// come from a season's GeneratorConfig.
+type Configurable interface {
+	SetConfig(cfg models.GeneratorConfig)
+}
+
+// Configure applies cfg to p when the model supports it.
+func Configure(p interface{}, cfg models.GeneratorConfig) {
+	if c, ok := p.(Configurable); ok {
+		c.SetConfig(cfg)
+	}
+}
+
+// Historical is implemented by models that look at the results played so
+// far, such as their kickoffs for rest days.
+type Historical interface {
+	SetPlayed(matches []models.MatchResult)
+}
+
 // Bivariate Poisson Monte Carlo
 func NewBivariatePoissonMonteCarlo() Predictor {
-	return &BivariatePoissonMC{}
+	return &BivariatePoissonMC{rng: newRNG(), cfg: models.DefaultGeneratorConfig()}
 }
 
-type BivariatePoissonMC struct{}
+type BivariatePoissonMC struct {
+	rng *rand.Rand
+	cfg models.GeneratorConfig
+}
 
 func (b *BivariatePoissonMC) Name() string { return "Bivariate Poisson" }
 
+// SetConfig implements Configurable.
+func (b *BivariatePoissonMC) SetConfig(cfg models.GeneratorConfig) { b.cfg = cfg }
+
 func (b *BivariatePoissonMC) Predict(teams []models.Team, table models.LeagueTable, remain []interfaces.Matchup, sims int) (map[int]float64, error) {
 	wins := make(map[int]int)
 	teamMap := make(map[int]models.Team)
@@ -42,14 +72,7 @@ func (b *BivariatePoissonMC) Predict(teams []models.Team, table models.LeagueTab
 		simTable := make(models.LeagueTable, len(table))
 		copy(simTable, table)
 		for _, m := range remain {
-			home := teamMap[m.HomeTeamID]
-			away := teamMap[m.AwayTeamID]
-			// Simple bivariate Poisson: add a shared component
-			lambdaHome := float64(home.Strength) / 50.0
-			lambdaAway := float64(away.Strength) / 50.0
-			lambdaShared := 0.3
-			hg := samplePoisson(lambdaHome+lambdaShared, rng)
-			ag := samplePoisson(lambdaAway+lambdaShared, rng)
+			hg, ag := sampleBivariate(b.cfg, teamMap[m.HomeTeamID], teamMap[m.AwayTeamID], rng)
 			ApplyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
 		}
 		champ := FindChampion(simTable)
@@ -62,15 +85,36 @@ func (b *BivariatePoissonMC) Predict(teams []models.Team, table models.LeagueTab
 	return probs, nil
 }
 
+// Generate implements interfaces.MatchGenerator.
+func (b *BivariatePoissonMC) Generate(home, away models.Team) (models.MatchResult, error) {
+	hg, ag := sampleBivariate(b.cfg, home, away, b.rng)
+	return newResult(home, away, hg, ag), nil
+}
+
+func sampleBivariate(cfg models.GeneratorConfig, home, away models.Team, rng *rand.Rand) (int, int) {
+	// Simple bivariate Poisson: both sides share a common component, taken
+	// out of each side's λ so the expected goals still match cfg.
+	lambdaHome, lambdaAway := cfg.Lambdas(home.Strength, away.Strength)
+	lambdaShared := math.Min(0.3, math.Min(lambdaHome, lambdaAway))
+	shared := samplePoisson(lambdaShared, rng)
+	return cfg.SampleGoals(lambdaHome-lambdaShared, rng) + shared, cfg.SampleGoals(lambdaAway-lambdaShared, rng) + shared
+}
+
 // Zero-Inflated Poisson Monte Carlo
 func NewZeroInflatedPoissonMonteCarlo() Predictor {
-	return &ZeroInflatedPoissonMC{}
+	return &ZeroInflatedPoissonMC{rng: newRNG(), cfg: models.DefaultGeneratorConfig()}
 }
 
-type ZeroInflatedPoissonMC struct{}
+type ZeroInflatedPoissonMC struct {
+	rng *rand.Rand
+	cfg models.GeneratorConfig
+}
 
 func (z *ZeroInflatedPoissonMC) Name() string { return "Zero-Inflated Poisson" }
 
+// SetConfig implements Configurable.
+func (z *ZeroInflatedPoissonMC) SetConfig(cfg models.GeneratorConfig) { z.cfg = cfg }
+
 func (z *ZeroInflatedPoissonMC) Predict(teams []models.Team, table models.LeagueTable, remain []interfaces.Matchup, sims int) (map[int]float64, error) {
 	wins := make(map[int]int)
 	teamMap := make(map[int]models.Team)
@@ -82,18 +126,7 @@ func (z *ZeroInflatedPoissonMC) Predict(teams []models.Team, table models.League
 		simTable := make(models.LeagueTable, len(table))
 		copy(simTable, table)
 		for _, m := range remain {
-			home := teamMap[m.HomeTeamID]
-			away := teamMap[m.AwayTeamID]
-			lambdaHome := float64(home.Strength) / 50.0
-			lambdaAway := float64(away.Strength) / 50.0
-			zeroProb := 0.12 // 12% chance of 0-0 draw
-			if rng.Float64() < zeroProb {
-				hg, ag := 0, 0
-				ApplyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
-				continue
-			}
-			hg := samplePoisson(lambdaHome, rng)
-			ag := samplePoisson(lambdaAway, rng)
+			hg, ag := sampleZeroInflated(z.cfg, teamMap[m.HomeTeamID], teamMap[m.AwayTeamID], rng)
 			ApplyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
 		}
 		champ := FindChampion(simTable)
@@ -106,6 +139,21 @@ func (z *ZeroInflatedPoissonMC) Predict(teams []models.Team, table models.League
 	return probs, nil
 }
 
+// Generate implements interfaces.MatchGenerator.
+func (z *ZeroInflatedPoissonMC) Generate(home, away models.Team) (models.MatchResult, error) {
+	hg, ag := sampleZeroInflated(z.cfg, home, away, z.rng)
+	return newResult(home, away, hg, ag), nil
+}
+
+func sampleZeroInflated(cfg models.GeneratorConfig, home, away models.Team, rng *rand.Rand) (int, int) {
+	lambdaHome, lambdaAway := cfg.Lambdas(home.Strength, away.Strength)
+	zeroProb := 0.12 // 12% chance of 0-0 draw
+	if rng.Float64() < zeroProb {
+		return 0, 0
+	}
+	return cfg.SampleGoals(lambdaHome, rng), cfg.SampleGoals(lambdaAway, rng)
+}
+
 // Gradient Boosted Trees Monte Carlo
 func NewGradientBoostedTreesMonteCarlo() Predictor {
 	return &GradientBoostedTreesMC{}
@@ -0,0 +1,96 @@
+package predictor
+
+import (
+    "math"
+    "math/rand"
+    "time"
+
+    "github.com/musta/insider-league/internal/dates"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Rest-day effects of RestMC.
+const (
+    FullRestDays      = 4.0  // days of rest after which a side is fully fresh
+    RestPenalty       = 0.04 // share of expected goals lost per day short of FullRestDays
+    CongestionFree    = 2    // games in the congestion window that cost nothing
+    CongestionPenalty = 0.03 // share lost per game beyond CongestionFree
+    MinFreshness      = 0.75
+)
+
+// Freshness returns how much of its usual game a side brings after days
+// of rest (0 = unknown) with recent games in the dates.CongestionWindow
+// before kickoff.
+func Freshness(days float64, recent int) float64 {
+    f := 1.0
+    if days > 0 && days < FullRestDays {
+        f -= RestPenalty * (FullRestDays - days)
+    }
+    if recent > CongestionFree {
+        f -= CongestionPenalty * float64(recent-CongestionFree)
+    }
+    return math.Max(f, MinFreshness)
+}
+
+// RestMC is the Poisson model with each side's λ scaled by how rested it
+// is relative to its opponent. Without season dates it plays like
+// PoissonMC.
+type RestMC struct {
+    cfg    models.GeneratorConfig
+    played []models.MatchResult
+}
+
+// NewRestMonteCarlo creates a rest-adjusted Poisson predictor.
+func NewRestMonteCarlo() Predictor {
+    return &RestMC{cfg: models.DefaultGeneratorConfig()}
+}
+
+func (p *RestMC) Name() string { return "Rest-Adjusted Poisson" }
+
+// SetConfig implements Configurable.
+func (p *RestMC) SetConfig(cfg models.GeneratorConfig) { p.cfg = cfg }
+
+// SetPlayed implements Historical; the kickoffs of played results give
+// the rest before each side's next fixture.
+func (p *RestMC) SetPlayed(matches []models.MatchResult) { p.played = matches }
+
+func (p *RestMC) Predict(
+    teams []models.Team,
+    table models.LeagueTable,
+    remaining []interfaces.Matchup,
+    sims int,
+) (map[int]float64, error) {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    // Rest only depends on the calendar, so the λs are fixed up front.
+    rests := dates.RestBefore(p.played, remaining)
+    lambdas := make([][2]float64, len(remaining))
+    for i, m := range remaining {
+        hl, al := p.cfg.Lambdas(byID[m.HomeTeamID].Strength, byID[m.AwayTeamID].Strength)
+        fh := Freshness(rests[i].HomeDays, rests[i].HomeRecent)
+        fa := Freshness(rests[i].AwayDays, rests[i].AwayRecent)
+        lambdas[i] = [2]float64{hl * fh / fa, al * fa / fh}
+    }
+
+    wins := make(map[int]int)
+    rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+    for i := 0; i < sims; i++ {
+        simTable := make(models.LeagueTable, len(table))
+        copy(simTable, table)
+        for j, m := range remaining {
+            hg := p.cfg.SampleGoals(lambdas[j][0], rng)
+            ag := p.cfg.SampleGoals(lambdas[j][1], rng)
+            applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
+        }
+        wins[findChampion(simTable)]++
+    }
+
+    probs := make(map[int]float64, len(wins))
+    for id, cnt := range wins {
+        probs[id] = float64(cnt) / float64(sims)
+    }
+    return probs, nil
+}
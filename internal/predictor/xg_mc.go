@@ -0,0 +1,121 @@
+package predictor
+
+import (
+    "math/rand"
+    "time"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// XGPrior is how many expected goals of average play every rating of
+// XGMC starts from, so a few games move it only a little.
+const XGPrior = 6.0
+
+// XGRating is how a side's play so far compares with what its strength
+// promised: Attack is the share of its expected goals it created and
+// Defence the share it conceded, 1 being as expected.
+type XGRating struct {
+    Attack  float64
+    Defence float64
+}
+
+// XGRatings rates every team from the played results, using their xG
+// where it was recorded and the goals otherwise.
+func XGRatings(cfg models.GeneratorConfig, teams []models.Team, played []models.MatchResult) map[int]XGRating {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    type totals struct{ forXG, forExp, againstXG, againstExp float64 }
+    sums := make(map[int]*totals, len(teams))
+    for _, t := range teams {
+        sums[t.ID] = &totals{}
+    }
+    for _, m := range played {
+        home, away := sums[m.HomeTeamID], sums[m.AwayTeamID]
+        if home == nil || away == nil {
+            continue
+        }
+        hx, ax := float64(m.HomeGoals), float64(m.AwayGoals)
+        if m.Stats.HasXG() {
+            hx, ax = m.Stats.HomeXG, m.Stats.AwayXG
+        }
+        hl, al := cfg.Lambdas(byID[m.HomeTeamID].Strength, byID[m.AwayTeamID].Strength)
+        home.forXG += hx
+        home.forExp += hl
+        home.againstXG += ax
+        home.againstExp += al
+        away.forXG += ax
+        away.forExp += al
+        away.againstXG += hx
+        away.againstExp += hl
+    }
+
+    ratings := make(map[int]XGRating, len(teams))
+    for id, s := range sums {
+        ratings[id] = XGRating{
+            Attack:  (s.forXG + XGPrior) / (s.forExp + XGPrior),
+            Defence: (s.againstXG + XGPrior) / (s.againstExp + XGPrior),
+        }
+    }
+    return ratings
+}
+
+// XGMC is the Poisson model with each side's λ scaled by the XGRatings of
+// both teams. Before any game has been played it plays like PoissonMC.
+type XGMC struct {
+    cfg    models.GeneratorConfig
+    played []models.MatchResult
+}
+
+// NewXGMonteCarlo creates an xG-rated Poisson predictor.
+func NewXGMonteCarlo() Predictor {
+    return &XGMC{cfg: models.DefaultGeneratorConfig()}
+}
+
+func (p *XGMC) Name() string { return "xG-Rated Poisson" }
+
+// SetConfig implements Configurable.
+func (p *XGMC) SetConfig(cfg models.GeneratorConfig) { p.cfg = cfg }
+
+// SetPlayed implements Historical; the played results give the ratings.
+func (p *XGMC) SetPlayed(matches []models.MatchResult) { p.played = matches }
+
+func (p *XGMC) Predict(
+    teams []models.Team,
+    table models.LeagueTable,
+    remaining []interfaces.Matchup,
+    sims int,
+) (map[int]float64, error) {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    ratings := XGRatings(p.cfg, teams, p.played)
+    lambdas := make([][2]float64, len(remaining))
+    for i, m := range remaining {
+        hl, al := p.cfg.Lambdas(byID[m.HomeTeamID].Strength, byID[m.AwayTeamID].Strength)
+        home, away := ratings[m.HomeTeamID], ratings[m.AwayTeamID]
+        lambdas[i] = [2]float64{hl * home.Attack * away.Defence, al * away.Attack * home.Defence}
+    }
+
+    wins := make(map[int]int)
+    rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+    for i := 0; i < sims; i++ {
+        simTable := make(models.LeagueTable, len(table))
+        copy(simTable, table)
+        for j, m := range remaining {
+            hg := p.cfg.SampleGoals(lambdas[j][0], rng)
+            ag := p.cfg.SampleGoals(lambdas[j][1], rng)
+            applyResultByID(&simTable, m.HomeTeamID, m.AwayTeamID, hg, ag)
+        }
+        wins[findChampion(simTable)]++
+    }
+
+    probs := make(map[int]float64, len(wins))
+    for id, cnt := range wins {
+        probs[id] = float64(cnt) / float64(sims)
+    }
+    return probs, nil
+}
@@ -0,0 +1,94 @@
+package pyramid
+
+import (
+    "fmt"
+    "math/rand"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Odds is one team's chance of each end-of-season outcome in its division.
+type Odds struct {
+    TeamID     int     `json:"team_id"`
+    DivisionID int     `json:"division_id"`
+    Title      float64 `json:"title"`
+    Promotion  float64 `json:"promotion"` // automatic or through the playoff
+    Playoff    float64 `json:"playoff"`   // finishes in a playoff place
+    Relegation float64 `json:"relegation"`
+}
+
+// Predict plays the rest of the season sims times and counts where every
+// team ends up. Each run starts from table, plays the remaining fixtures
+// with a generator fresh from newGen, tabulating them with u, and settles
+// every division as Finish does.
+func Predict(divs []models.Division, teams []models.Team, table models.LeagueTable, remaining []interfaces.Matchup,
+    newGen interfaces.GeneratorFactory, u interfaces.TableUpdater, sims int, rng *rand.Rand) ([]Odds, error) {
+    if sims < 1 {
+        return nil, fmt.Errorf("%w: sims must be positive", ErrInvalidPyramid)
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    type counts struct{ title, promoted, playoff, relegated int }
+    tally := make(map[int]*counts, len(teams))
+    for _, d := range divs {
+        for _, id := range d.TeamIDs {
+            tally[id] = &counts{}
+        }
+    }
+
+    levels := sorted(divs)
+    for s := 0; s < sims; s++ {
+        gen, err := newGen()
+        if err != nil {
+            return nil, err
+        }
+        sim := make(models.LeagueTable, len(table))
+        copy(sim, table)
+        for _, f := range remaining {
+            m, err := gen.Generate(byID[f.HomeTeamID], byID[f.AwayTeamID])
+            if err != nil {
+                return nil, err
+            }
+            if sim, err = u.Update(sim, m); err != nil {
+                return nil, err
+            }
+        }
+        res, err := Finish(divs, teams, sim, nil, gen, rng)
+        if err != nil {
+            return nil, err
+        }
+        for i, dr := range res.Divisions {
+            tally[dr.ChampionID].title++
+            for _, id := range dr.Promoted {
+                tally[id].promoted++
+            }
+            for _, id := range dr.Relegated {
+                tally[id].relegated++
+            }
+            d := levels[i]
+            for _, row := range dr.Table[d.Promotion : d.Promotion+d.Playoff] {
+                tally[row.TeamID].playoff++
+            }
+        }
+    }
+
+    var odds []Odds
+    n := float64(sims)
+    for _, d := range levels {
+        for _, id := range d.TeamIDs {
+            c := tally[id]
+            odds = append(odds, Odds{
+                TeamID:     id,
+                DivisionID: d.ID,
+                Title:      float64(c.title) / n,
+                Promotion:  float64(c.promoted) / n,
+                Playoff:    float64(c.playoff) / n,
+                Relegation: float64(c.relegated) / n,
+            })
+        }
+    }
+    return odds, nil
+}
@@ -0,0 +1,84 @@
+// Package pyramid plays linked divisions: a season in every division,
+// promotion playoffs, and the rollover that moves teams up and down.
+package pyramid
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+
+    "github.com/musta/insider-league/internal/cup"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ErrInvalidPyramid is wrapped by every inconsistent pyramid.
+var ErrInvalidPyramid = errors.New("invalid pyramid")
+
+// PlayoffConfig is how promotion playoffs are played: two-legged ties and
+// a single-match final.
+var PlayoffConfig = cup.Config{Legs: 2, FinalLegs: 1, ExtraTime: true}
+
+// Validate checks that divs form a pyramid of teams: levels 1..n, no
+// promotion out of the top or relegation out of the bottom, and as many
+// teams going up from each division as come down from the one above.
+func Validate(divs []models.Division, teams []models.Team) error {
+    if len(divs) == 0 {
+        return fmt.Errorf("%w: no divisions", ErrInvalidPyramid)
+    }
+    byLevel := sorted(divs)
+    known := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        known[t.ID] = true
+    }
+    seen := make(map[int]bool, len(teams))
+
+    for i, d := range byLevel {
+        switch {
+        case d.Level != i+1:
+            return fmt.Errorf("%w: levels must run 1..%d without gaps", ErrInvalidPyramid, len(divs))
+        case d.Name == "":
+            return fmt.Errorf("%w: division at level %d needs a name", ErrInvalidPyramid, d.Level)
+        case d.Promotion < 0 || d.Relegation < 0 || d.Playoff < 0:
+            return fmt.Errorf("%w: %s: slot counts cannot be negative", ErrInvalidPyramid, d.Name)
+        case d.Playoff == 1:
+            return fmt.Errorf("%w: %s: a playoff needs at least 2 teams", ErrInvalidPyramid, d.Name)
+        case len(d.TeamIDs) < 2:
+            return fmt.Errorf("%w: %s needs at least 2 teams", ErrInvalidPyramid, d.Name)
+        case d.Promotion+d.Playoff+d.Relegation > len(d.TeamIDs):
+            return fmt.Errorf("%w: %s has more promotion, playoff and relegation places than teams", ErrInvalidPyramid, d.Name)
+        case i == 0 && d.Promotion+d.Playoff > 0:
+            return fmt.Errorf("%w: %s is the top division and cannot promote", ErrInvalidPyramid, d.Name)
+        case i == len(byLevel)-1 && d.Relegation > 0:
+            return fmt.Errorf("%w: %s is the bottom division and cannot relegate", ErrInvalidPyramid, d.Name)
+        case i > 0 && goingUp(d) != byLevel[i-1].Relegation:
+            return fmt.Errorf("%w: %s sends %d teams up but %s relegates %d",
+                ErrInvalidPyramid, d.Name, goingUp(d), byLevel[i-1].Name, byLevel[i-1].Relegation)
+        }
+        for _, id := range d.TeamIDs {
+            if !known[id] {
+                return fmt.Errorf("%w: unknown team %d in %s", ErrInvalidPyramid, id, d.Name)
+            }
+            if seen[id] {
+                return fmt.Errorf("%w: team %d is in two divisions", ErrInvalidPyramid, id)
+            }
+            seen[id] = true
+        }
+    }
+    return nil
+}
+
+// goingUp is the number of teams d promotes, counting the playoff winner.
+func goingUp(d models.Division) int {
+    if d.Playoff > 0 {
+        return d.Promotion + 1
+    }
+    return d.Promotion
+}
+
+// sorted returns a copy of divs ordered by level.
+func sorted(divs []models.Division) []models.Division {
+    out := make([]models.Division, len(divs))
+    copy(out, divs)
+    sort.Slice(out, func(i, j int) bool { return out[i].Level < out[j].Level })
+    return out
+}
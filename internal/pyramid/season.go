@@ -0,0 +1,250 @@
+package pyramid
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+
+    "github.com/musta/insider-league/internal/cup"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/predictor"
+    "github.com/musta/insider-league/internal/schedule"
+)
+
+// DivisionResult is one division's season: its final table, the playoff
+// and the teams that change division.
+type DivisionResult struct {
+    DivisionID int                  `json:"division_id"`
+    Name       string               `json:"name"`
+    Matches    []models.MatchResult `json:"matches"`
+    Table      models.LeagueTable   `json:"table"`
+    Playoff    *cup.Result          `json:"playoff,omitempty"`
+    ChampionID int                  `json:"champion_id"`
+    Promoted   []int                `json:"promoted"`
+    Relegated  []int                `json:"relegated"`
+}
+
+// SeasonResult is a season of every division, top level first.
+type SeasonResult struct {
+    Divisions []DivisionResult `json:"divisions"`
+}
+
+// PlaySeason plays a double round-robin in every division with gen,
+// tabulates it with u and plays the promotion playoffs.
+func PlaySeason(divs []models.Division, teams []models.Team, gen interfaces.MatchGenerator, u interfaces.TableUpdater, rng *rand.Rand) (SeasonResult, error) {
+    if err := Validate(divs, teams); err != nil {
+        return SeasonResult{}, err
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+
+    var res SeasonResult
+    for _, d := range sorted(divs) {
+        dr, err := playDivision(d, byID, gen, u, rng)
+        if err != nil {
+            return res, fmt.Errorf("%s: %w", d.Name, err)
+        }
+        res.Divisions = append(res.Divisions, dr)
+    }
+    return res, nil
+}
+
+// playDivision plays one division's season.
+func playDivision(d models.Division, byID map[int]models.Team, gen interfaces.MatchGenerator, u interfaces.TableUpdater, rng *rand.Rand) (DivisionResult, error) {
+    dr := DivisionResult{DivisionID: d.ID, Name: d.Name}
+    members := make([]models.Team, len(d.TeamIDs))
+    dr.Table = make(models.LeagueTable, len(d.TeamIDs))
+    for i, id := range d.TeamIDs {
+        members[i] = byID[id]
+        dr.Table[i] = models.TeamStanding{TeamID: id, TeamName: byID[id].Name}
+    }
+
+    fixtures, err := schedule.RoundRobin{Legs: 2}.Generate(members)
+    if err != nil {
+        return dr, err
+    }
+    for _, f := range fixtures {
+        m, err := gen.Generate(byID[f.HomeTeamID], byID[f.AwayTeamID])
+        if err != nil {
+            return dr, err
+        }
+        m.Week = f.Week
+        if dr.Table, err = u.Update(dr.Table, m); err != nil {
+            return dr, err
+        }
+        dr.Matches = append(dr.Matches, m)
+    }
+    dr.Table.Sort()
+    return settle(d, dr, members, gen, rng)
+}
+
+// Finish settles a season whose league matches were stored rather than
+// simulated. Each division's table is its members' rows of table, sorted
+// into final positions, and its matches are those of played between its
+// members. Only the promotion playoffs are played, with gen.
+func Finish(divs []models.Division, teams []models.Team, table models.LeagueTable, played []models.MatchResult,
+    gen interfaces.MatchGenerator, rng *rand.Rand) (SeasonResult, error) {
+    if err := Validate(divs, teams); err != nil {
+        return SeasonResult{}, err
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+
+    var res SeasonResult
+    for _, d := range sorted(divs) {
+        dr := DivisionResult{DivisionID: d.ID, Name: d.Name}
+        in := make(map[int]bool, len(d.TeamIDs))
+        members := make([]models.Team, len(d.TeamIDs))
+        for i, id := range d.TeamIDs {
+            in[id] = true
+            members[i] = byID[id]
+        }
+        for _, row := range table {
+            if in[row.TeamID] {
+                dr.Table = append(dr.Table, row)
+            }
+        }
+        if len(dr.Table) != len(d.TeamIDs) {
+            return res, fmt.Errorf("%w: %s: not every team is in the table", ErrInvalidPyramid, d.Name)
+        }
+        for _, m := range played {
+            if in[m.HomeTeamID] && in[m.AwayTeamID] {
+                dr.Matches = append(dr.Matches, m)
+            }
+        }
+        sort.SliceStable(dr.Matches, func(i, j int) bool { return dr.Matches[i].Week < dr.Matches[j].Week })
+        dr.Table.Sort()
+        dr, err := settle(d, dr, members, gen, rng)
+        if err != nil {
+            return res, fmt.Errorf("%s: %w", d.Name, err)
+        }
+        res.Divisions = append(res.Divisions, dr)
+    }
+    return res, nil
+}
+
+// settle picks d's champion and the teams that change division from its
+// final table, playing the promotion playoff with gen.
+func settle(d models.Division, dr DivisionResult, members []models.Team, gen interfaces.MatchGenerator, rng *rand.Rand) (DivisionResult, error) {
+    dr.ChampionID = dr.Table[0].TeamID
+
+    for _, row := range dr.Table[:d.Promotion] {
+        dr.Promoted = append(dr.Promoted, row.TeamID)
+    }
+    for _, row := range dr.Table[len(dr.Table)-d.Relegation:] {
+        dr.Relegated = append(dr.Relegated, row.TeamID)
+    }
+    if d.Playoff > 0 {
+        var ids []int
+        for _, row := range dr.Table[d.Promotion : d.Promotion+d.Playoff] {
+            ids = append(ids, row.TeamID)
+        }
+        po, err := cup.Simulate(cup.Seeded(ids), members, gen, PlayoffConfig, rng)
+        if err != nil {
+            return dr, fmt.Errorf("playoff: %w", err)
+        }
+        dr.Playoff = &po
+        dr.Promoted = append(dr.Promoted, po.WinnerID)
+    }
+    return dr, nil
+}
+
+// Rollover returns next season's pyramid: promoted teams move up a level,
+// relegated teams down, everyone else stays.
+func Rollover(divs []models.Division, res SeasonResult) []models.Division {
+    byLevel := sorted(divs)
+    results := make(map[int]DivisionResult, len(res.Divisions))
+    for _, dr := range res.Divisions {
+        results[dr.DivisionID] = dr
+    }
+
+    next := make([]models.Division, len(byLevel))
+    for i, d := range byLevel {
+        moving := make(map[int]bool)
+        for _, id := range results[d.ID].Promoted {
+            moving[id] = true
+        }
+        for _, id := range results[d.ID].Relegated {
+            moving[id] = true
+        }
+        var ids []int
+        for _, id := range d.TeamIDs {
+            if !moving[id] {
+                ids = append(ids, id)
+            }
+        }
+        if i > 0 {
+            ids = append(ids, results[byLevel[i-1].ID].Relegated...)
+        }
+        if i < len(byLevel)-1 {
+            ids = append(ids, results[byLevel[i+1].ID].Promoted...)
+        }
+        sort.Ints(ids)
+        d.TeamIDs = ids
+        next[i] = d
+    }
+    return next
+}
+
+// Fixtures returns a double round-robin in every division, all starting
+// in week 1, ordered by week.
+func Fixtures(divs []models.Division, teams []models.Team) ([]interfaces.Matchup, error) {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    var all []interfaces.Matchup
+    for _, d := range sorted(divs) {
+        members := make([]models.Team, len(d.TeamIDs))
+        for i, id := range d.TeamIDs {
+            members[i] = byID[id]
+        }
+        fixtures, err := schedule.RoundRobin{Legs: 2}.Generate(members)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", d.Name, err)
+        }
+        all = append(all, fixtures...)
+    }
+    sort.SliceStable(all, func(i, j int) bool { return all[i].Week < all[j].Week })
+    return all, nil
+}
+
+// CarryRatings replays every match of the season, playoffs included,
+// through Elo and returns the teams with their new ratings. Unrated teams
+// start from predictor.BaseRating.
+func CarryRatings(teams []models.Team, res SeasonResult) []models.Team {
+    ratings := make(map[int]float64, len(teams))
+    for _, t := range teams {
+        ratings[t.ID] = predictor.StartRating(t)
+    }
+    apply := func(m models.MatchResult) {
+        predictor.UpdateElo(ratings, m.HomeTeamID, m.AwayTeamID, m.HomeGoals, m.AwayGoals)
+    }
+    for _, dr := range res.Divisions {
+        for _, m := range dr.Matches {
+            apply(m)
+        }
+        if dr.Playoff == nil {
+            continue
+        }
+        for _, round := range dr.Playoff.Rounds {
+            for _, tie := range round.Ties {
+                for _, m := range tie.Legs {
+                    apply(m)
+                }
+            }
+        }
+    }
+
+    out := make([]models.Team, len(teams))
+    for i, t := range teams {
+        t.Rating = ratings[t.ID]
+        out[i] = t
+    }
+    return out
+}
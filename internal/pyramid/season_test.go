@@ -0,0 +1,65 @@
+package pyramid
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/musta/insider-league/internal/models"
+)
+
+func TestFinishRanksByFinalPosition(t *testing.T) {
+    teams := []models.Team{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}
+    divs := []models.Division{
+        {ID: 10, Name: "Top", Level: 1, Relegation: 1, TeamIDs: []int{1, 2, 3}},
+        {ID: 20, Name: "Second", Level: 2, Promotion: 1, TeamIDs: []int{4, 5, 6}},
+    }
+    // In team ID order, as the repository returns it.
+    table := models.LeagueTable{
+        {TeamID: 1, Points: 3},
+        {TeamID: 2, Points: 9},
+        {TeamID: 3, Points: 6},
+        {TeamID: 4, Points: 6, GoalDiff: 1},
+        {TeamID: 5, Points: 6, GoalDiff: 2},
+        {TeamID: 6, Points: 0},
+    }
+    played := []models.MatchResult{
+        {Week: 2, HomeTeamID: 2, AwayTeamID: 1},
+        {Week: 1, HomeTeamID: 1, AwayTeamID: 4}, // across divisions: in neither
+        {Week: 1, HomeTeamID: 5, AwayTeamID: 6},
+    }
+
+    res, err := Finish(divs, teams, table, played, nil, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(res.Divisions) != 2 {
+        t.Fatalf("got %d divisions, want 2", len(res.Divisions))
+    }
+    top, second := res.Divisions[0], res.Divisions[1]
+    if top.ChampionID != 2 || second.ChampionID != 5 {
+        t.Errorf("champions %d and %d, want 2 and 5", top.ChampionID, second.ChampionID)
+    }
+    if !reflect.DeepEqual(top.Relegated, []int{1}) {
+        t.Errorf("relegated %v, want [1]", top.Relegated)
+    }
+    if !reflect.DeepEqual(second.Promoted, []int{5}) {
+        t.Errorf("promoted %v, want [5]", second.Promoted)
+    }
+    if len(top.Matches) != 1 || len(second.Matches) != 1 {
+        t.Errorf("got %d and %d matches, want 1 and 1", len(top.Matches), len(second.Matches))
+    }
+
+    next := Rollover(divs, res)
+    if !reflect.DeepEqual(next[0].TeamIDs, []int{2, 3, 5}) || !reflect.DeepEqual(next[1].TeamIDs, []int{1, 4, 6}) {
+        t.Errorf("next season %v and %v, want [2 3 5] and [1 4 6]", next[0].TeamIDs, next[1].TeamIDs)
+    }
+}
+
+func TestFinishNeedsEveryTeamInTheTable(t *testing.T) {
+    teams := []models.Team{{ID: 1}, {ID: 2}, {ID: 3}}
+    divs := []models.Division{{ID: 10, Name: "Only", Level: 1, TeamIDs: []int{1, 2, 3}}}
+    table := models.LeagueTable{{TeamID: 1}, {TeamID: 2}}
+    if _, err := Finish(divs, teams, table, nil, nil, nil); err == nil {
+        t.Fatal("want an error for a team missing from the table")
+    }
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// adjustmentColumns lists the adjustments columns read by scanAdjustment.
+const adjustmentColumns = `id, kind, team_id, points, week,
+    COALESCE(home_team_id, 0), COALESCE(away_team_id, 0), reason, created_at`
+
+func scanAdjustment(row rowScanner, a *models.Adjustment) error {
+    return row.Scan(&a.ID, &a.Kind, &a.TeamID, &a.Points, &a.Week,
+        &a.HomeTeamID, &a.AwayTeamID, &a.Reason, &a.CreatedAt)
+}
+
+// ListAdjustments returns the current season's adjustments ordered by
+// week and id.
+func (r *PostgresRepo) ListAdjustments() ([]models.Adjustment, error) {
+    rows, err := r.db.Query(`
+        SELECT ` + adjustmentColumns + `
+        FROM adjustments
+        WHERE season_id = ` + currentSeasonSQL + `
+        ORDER BY week, id
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var adjs []models.Adjustment
+    for rows.Next() {
+        var a models.Adjustment
+        if err := scanAdjustment(rows, &a); err != nil {
+            return nil, err
+        }
+        adjs = append(adjs, a)
+    }
+    return adjs, rows.Err()
+}
+
+func (r *PostgresRepo) GetAdjustment(id int) (models.Adjustment, error) {
+    var a models.Adjustment
+    err := scanAdjustment(r.db.QueryRow(`
+        SELECT `+adjustmentColumns+`
+        FROM adjustments
+        WHERE season_id = `+currentSeasonSQL+` AND id = $1
+    `, id), &a)
+    if err == sql.ErrNoRows {
+        return a, fmt.Errorf("adjustment %d: %w", id, interfaces.ErrNotFound)
+    }
+    return a, err
+}
+
+func (r *PostgresRepo) SaveAdjustment(a models.Adjustment) (models.Adjustment, error) {
+    err := r.db.QueryRow(`
+        INSERT INTO adjustments (season_id, kind, team_id, points, week, home_team_id, away_team_id, reason)
+        VALUES (`+currentSeasonSQL+`, $1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, 0), $7)
+        RETURNING id, created_at
+    `, a.Kind, a.TeamID, a.Points, a.Week, a.HomeTeamID, a.AwayTeamID, a.Reason).Scan(&a.ID, &a.CreatedAt)
+    return a, translateErr(err)
+}
+
+func (r *PostgresRepo) DeleteAdjustment(id int) error {
+    res, err := r.db.Exec(`
+        DELETE FROM adjustments
+        WHERE season_id = `+currentSeasonSQL+` AND id = $1
+    `, id)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("adjustment %d: %w", id, interfaces.ErrNotFound)
+    }
+    return nil
+}
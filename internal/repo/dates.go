@@ -0,0 +1,59 @@
+package repo
+
+import (
+    "database/sql"
+    "time"
+
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// GetDates returns the current season's calendar, or a zero SeasonDates
+// when the season has none.
+func (r *PostgresRepo) GetDates() (models.SeasonDates, error) {
+    var d models.SeasonDates
+    var start time.Time
+    var midweek pq.Int64Array
+    err := r.db.QueryRow(`
+        SELECT start_date, days_between, kickoff, midweek_weeks, midweek_offset, midweek_kickoff, timezone
+        FROM season_dates
+        WHERE season_id = `+currentSeasonSQL,
+    ).Scan(&start, &d.DaysBetween, &d.Kickoff, &midweek, &d.MidweekOffset, &d.MidweekKickoff, &d.Timezone)
+    if err == sql.ErrNoRows {
+        return models.SeasonDates{}, nil
+    }
+    if err != nil {
+        return d, err
+    }
+    d.StartDate = start.Format(time.DateOnly)
+    for _, w := range midweek {
+        d.MidweekWeeks = append(d.MidweekWeeks, int(w))
+    }
+    return d, nil
+}
+
+// SaveDates replaces the current season's calendar. A SeasonDates without
+// a start date removes it.
+func (r *PostgresRepo) SaveDates(d models.SeasonDates) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if _, err := tx.db.Exec(`DELETE FROM season_dates WHERE season_id = ` + currentSeasonSQL); err != nil {
+            return err
+        }
+        if !d.Enabled() {
+            return nil
+        }
+        _, err := tx.db.Exec(`
+            INSERT INTO season_dates
+                (season_id, start_date, days_between, kickoff, midweek_weeks, midweek_offset, midweek_kickoff, timezone)
+            VALUES (`+currentSeasonSQL+`, $1, $2, $3, $4, $5, $6, $7)
+        `, d.StartDate, d.DaysBetween, d.Kickoff, pq.Array(d.MidweekWeeks), d.MidweekOffset, d.MidweekKickoff, d.Timezone)
+        return err
+    })
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+    return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,160 @@
+package repo
+
+import (
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// statsColumns lists the columns match_stats, historical_match_stats and
+// snapshot_match_stats share, in the order statsFields returns them.
+const statsColumns = `home_xg, away_xg, home_shots, away_shots,
+    home_shots_on_target, away_shots_on_target, home_possession, away_possession,
+    home_corners, away_corners, home_yellow_cards, away_yellow_cards,
+    home_red_cards, away_red_cards`
+
+// statsPlaceholders are the parameters $2..$15 for statsColumns, after
+// the key in $1.
+const statsPlaceholders = `$2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15`
+
+// statsFields returns pointers to the fields of s in statsColumns order.
+func statsFields(s *models.MatchStats) []interface{} {
+    return []interface{}{
+        &s.HomeXG, &s.AwayXG, &s.HomeShots, &s.AwayShots,
+        &s.HomeShotsOnTarget, &s.AwayShotsOnTarget, &s.HomePossession, &s.AwayPossession,
+        &s.HomeCorners, &s.AwayCorners, &s.HomeYellowCards, &s.AwayYellowCards,
+        &s.HomeRedCards, &s.AwayRedCards,
+    }
+}
+
+// saveStats stores s under key in table: match_stats keyed by match_id,
+// historical_match_stats keyed by historical_id or snapshot_match_stats
+// keyed by snapshot_match_id.
+func (r *PostgresRepo) saveStats(table, keyColumn string, key int, s models.MatchStats) error {
+    args := []interface{}{key}
+    for _, f := range statsFields(&s) {
+        args = append(args, f)
+    }
+    _, err := r.db.Exec(`
+        INSERT INTO `+table+` (`+keyColumn+`, `+statsColumns+`)
+        VALUES ($1, `+statsPlaceholders+`)
+    `, args...)
+    return err
+}
+
+// loadStats returns the stats stored in table for keys.
+func (r *PostgresRepo) loadStats(table, keyColumn string, keys []int) (map[int]*models.MatchStats, error) {
+    rows, err := r.db.Query(`
+        SELECT `+keyColumn+`, `+statsColumns+`
+        FROM `+table+`
+        WHERE `+keyColumn+` = ANY($1)
+    `, pq.Array(keys))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    stats := make(map[int]*models.MatchStats)
+    for rows.Next() {
+        var key int
+        s := new(models.MatchStats)
+        if err := rows.Scan(append([]interface{}{&key}, statsFields(s)...)...); err != nil {
+            return nil, err
+        }
+        stats[key] = s
+    }
+    return stats, rows.Err()
+}
+
+// saveDetails replaces the stats and goals of the current season's result
+// of m's fixture, found by ID or by week and teams, with m's.
+func (r *PostgresRepo) saveDetails(m models.MatchResult) error {
+    var id int
+    err := r.db.QueryRow(`
+        SELECT id
+        FROM matches
+        WHERE season_id = `+currentSeasonSQL+`
+          AND CASE WHEN $4 > 0 THEN id = $4
+                   ELSE week = $1 AND home_team_id = $2 AND away_team_id = $3 END
+    `, m.Week, m.HomeTeamID, m.AwayTeamID, m.ID).Scan(&id)
+    if err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM match_stats WHERE match_id = $1`, id); err != nil {
+        return err
+    }
+    if _, err := r.db.Exec(`DELETE FROM match_goals WHERE match_id = $1`, id); err != nil {
+        return err
+    }
+    if m.Stats != nil {
+        if err := r.saveStats("match_stats", "match_id", id, *m.Stats); err != nil {
+            return err
+        }
+    }
+    return r.saveGoals("match_goals", "match_id", id, m.Goals)
+}
+
+// saveGoals stores goals under key in table, which is match_goals keyed
+// by match_id or snapshot_match_goals keyed by snapshot_match_id.
+func (r *PostgresRepo) saveGoals(table, keyColumn string, key int, goals []models.Goal) error {
+    for i, g := range goals {
+        if _, err := r.db.Exec(`
+            INSERT INTO `+table+` (`+keyColumn+`, seq, minute, added_time, team_id, scorer, penalty, own_goal)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        `, key, i, g.Minute, g.AddedTime, g.TeamID, g.Scorer, g.Penalty, g.OwnGoal); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// loadGoals returns the goals stored in table for keys, in the order they
+// were scored.
+func (r *PostgresRepo) loadGoals(table, keyColumn string, keys []int) (map[int][]models.Goal, error) {
+    rows, err := r.db.Query(`
+        SELECT `+keyColumn+`, minute, added_time, team_id, scorer, penalty, own_goal
+        FROM `+table+`
+        WHERE `+keyColumn+` = ANY($1)
+        ORDER BY `+keyColumn+`, seq
+    `, pq.Array(keys))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    goals := make(map[int][]models.Goal)
+    for rows.Next() {
+        var key int
+        var g models.Goal
+        if err := rows.Scan(&key, &g.Minute, &g.AddedTime, &g.TeamID, &g.Scorer, &g.Penalty, &g.OwnGoal); err != nil {
+            return nil, err
+        }
+        goals[key] = append(goals[key], g)
+    }
+    return goals, rows.Err()
+}
+
+// loadDetails fills in the stats and goals of matches, which must have
+// their IDs.
+func (r *PostgresRepo) loadDetails(matches []models.MatchResult) error {
+    if len(matches) == 0 {
+        return nil
+    }
+    ids := make([]int, len(matches))
+    for i, m := range matches {
+        ids[i] = m.ID
+    }
+    stats, err := r.loadStats("match_stats", "match_id", ids)
+    if err != nil {
+        return err
+    }
+    goals, err := r.loadGoals("match_goals", "match_id", ids)
+    if err != nil {
+        return err
+    }
+
+    for i := range matches {
+        matches[i].Stats = stats[matches[i].ID]
+        matches[i].Goals = goals[matches[i].ID]
+    }
+    return nil
+}
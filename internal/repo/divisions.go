@@ -0,0 +1,83 @@
+package repo
+
+import (
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ListDivisions returns the pyramid ordered by level, each division with
+// its team ids in ascending order.
+func (r *PostgresRepo) ListDivisions() ([]models.Division, error) {
+    rows, err := r.db.Query(`
+        SELECT d.id, d.name, d.level, d.promotion, d.relegation, d.playoff,
+               COALESCE(array_agg(dt.team_id ORDER BY dt.team_id) FILTER (WHERE dt.team_id IS NOT NULL), '{}')
+        FROM divisions d
+        LEFT JOIN division_teams dt ON dt.division_id = d.id
+        GROUP BY d.id
+        ORDER BY d.level
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var divs []models.Division
+    for rows.Next() {
+        var d models.Division
+        var ids pq.Int64Array
+        if err := rows.Scan(&d.ID, &d.Name, &d.Level, &d.Promotion, &d.Relegation, &d.Playoff, &ids); err != nil {
+            return nil, err
+        }
+        d.TeamIDs = make([]int, len(ids))
+        for i, id := range ids {
+            d.TeamIDs[i] = int(id)
+        }
+        divs = append(divs, d)
+    }
+    return divs, rows.Err()
+}
+
+// ReplaceDivisions swaps the whole pyramid for divs, keeping the ids of
+// divisions that have one.
+func (r *PostgresRepo) ReplaceDivisions(divs []models.Division) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        keep := make([]int64, 0, len(divs))
+        for _, d := range divs {
+            if d.ID != 0 {
+                keep = append(keep, int64(d.ID))
+            }
+        }
+        if _, err := tx.db.Exec(`DELETE FROM divisions WHERE id <> ALL($1)`, pq.Array(keep)); err != nil {
+            return err
+        }
+        // Levels are unique; park the survivors out of the way first.
+        if _, err := tx.db.Exec(`UPDATE divisions SET level = -level`); err != nil {
+            return err
+        }
+        if _, err := tx.db.Exec(`DELETE FROM division_teams`); err != nil {
+            return err
+        }
+        for _, d := range divs {
+            err := tx.db.QueryRow(`
+                INSERT INTO divisions (id, name, level, promotion, relegation, playoff)
+                VALUES (COALESCE(NULLIF($1, 0), nextval('divisions_id_seq')), $2, $3, $4, $5, $6)
+                ON CONFLICT (id) DO UPDATE
+                SET name = EXCLUDED.name, level = EXCLUDED.level, promotion = EXCLUDED.promotion,
+                    relegation = EXCLUDED.relegation, playoff = EXCLUDED.playoff
+                RETURNING id
+            `, d.ID, d.Name, d.Level, d.Promotion, d.Relegation, d.Playoff).Scan(&d.ID)
+            if err != nil {
+                return translateErr(err)
+            }
+            if _, err := tx.db.Exec(`
+                INSERT INTO division_teams (division_id, team_id)
+                SELECT $1, unnest($2::int[])
+            `, d.ID, pq.Array(d.TeamIDs)); err != nil {
+                return translateErr(err)
+            }
+        }
+        return nil
+    })
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+    "time"
+
+    "github.com/musta/insider-league/internal/models"
+)
+
+// logReset records that every match of every season is about to be wiped.
+// Match ids restart afterwards, so history readers ignore older events.
+func (r *PostgresRepo) logReset() error {
+    _, err := r.db.Exec(`
+        INSERT INTO match_events (season_id, action, actor)
+        SELECT id, 'reset', $1 FROM seasons
+    `, r.actor)
+    return err
+}
+
+func (r *PostgresRepo) GetMatchHistory(matchID int) ([]models.MatchEvent, error) {
+    // Only events since the last reset of the match's season belong to the
+    // current match with this id.
+    rows, err := r.db.Query(`
+        SELECT e.id, COALESCE(e.match_id, 0), e.season_id, e.action,
+               COALESCE(e.week, 0), COALESCE(e.home_team_id, 0), COALESCE(e.away_team_id, 0),
+               e.old_home_goals, e.old_away_goals, e.new_home_goals, e.new_away_goals,
+               e.actor, e.created_at
+        FROM match_events e
+        WHERE e.match_id = $1
+          AND e.id > COALESCE((
+              SELECT MAX(id) FROM match_events
+              WHERE action = 'reset' AND season_id = e.season_id
+          ), 0)
+        ORDER BY e.id
+    `, matchID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var events []models.MatchEvent
+    for rows.Next() {
+        var e models.MatchEvent
+        if err := rows.Scan(
+            &e.ID, &e.MatchID, &e.Season, &e.Action,
+            &e.Week, &e.HomeTeamID, &e.AwayTeamID,
+            &e.OldHomeGoals, &e.OldAwayGoals, &e.NewHomeGoals, &e.NewAwayGoals,
+            &e.Actor, &e.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        events = append(events, e)
+    }
+    return events, rows.Err()
+}
+
+func (r *PostgresRepo) GetMatchesAt(at time.Time) ([]models.MatchResult, error) {
+    // Replay the current season's events up to at: the latest event of each
+    // match since the season's last reset wins, and deleted matches drop out.
+    rows, err := r.db.Query(`
+        WITH ev AS (
+            SELECT *
+            FROM match_events
+            WHERE season_id = `+currentSeasonSQL+`
+              AND created_at <= $1
+              AND id > COALESCE((
+                  SELECT MAX(id) FROM match_events
+                  WHERE action = 'reset' AND created_at <= $1
+                    AND season_id = `+currentSeasonSQL+`
+              ), 0)
+        ), latest AS (
+            SELECT DISTINCT ON (match_id) *
+            FROM ev
+            WHERE match_id IS NOT NULL
+            ORDER BY match_id, id DESC
+        )
+        SELECT match_id, week, home_team_id, away_team_id, new_home_goals, new_away_goals
+        FROM latest
+        WHERE action <> 'delete'
+        ORDER BY week, match_id
+    `, at)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var matches []models.MatchResult
+    for rows.Next() {
+        var m models.MatchResult
+        if err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals); err != nil {
+            return nil, err
+        }
+        matches = append(matches, m)
+    }
+    return matches, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+    "database/sql"
+    "strings"
+
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// historyColumns lists the historical_matches columns read by scanHistory.
+const historyColumns = `id, source, competition, season, played_on,
+    home_team, away_team, home_goals, away_goals`
+
+func scanHistory(row rowScanner, m *models.HistoricalMatch) error {
+    var playedOn sql.NullTime
+    err := row.Scan(&m.ID, &m.Source, &m.Competition, &m.Season, &playedOn,
+        &m.HomeTeam, &m.AwayTeam, &m.HomeGoals, &m.AwayGoals)
+    m.PlayedOn = playedOn.Time
+    return err
+}
+
+// teamNames returns the lower-cased names imported results may know t by.
+func teamNames(t models.Team) []string {
+    names := []string{strings.ToLower(t.Name)}
+    if t.ShortName != "" {
+        names = append(names, strings.ToLower(t.ShortName))
+    }
+    return names
+}
+
+// ListMeetings returns every game between two teams: imported results in
+// date order, then the league's results season by season.
+func (r *PostgresRepo) ListMeetings(team1, team2 models.Team) ([]models.Meeting, error) {
+    names1, names2 := teamNames(team1), teamNames(team2)
+    rows, err := r.db.Query(`
+        SELECT `+historyColumns+`
+        FROM historical_matches
+        WHERE (lower(home_team) = ANY($1) AND lower(away_team) = ANY($2))
+           OR (lower(home_team) = ANY($2) AND lower(away_team) = ANY($1))
+        ORDER BY played_on NULLS FIRST, id
+    `, pq.Array(names1), pq.Array(names2))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var meetings []models.Meeting
+    for rows.Next() {
+        var h models.HistoricalMatch
+        if err := scanHistory(rows, &h); err != nil {
+            return nil, err
+        }
+        m := models.Meeting{
+            Source: h.Source, Competition: h.Competition, Season: h.Season, PlayedOn: h.PlayedOn,
+            HomeTeamID: team2.ID, AwayTeamID: team1.ID, HomeGoals: h.HomeGoals, AwayGoals: h.AwayGoals,
+        }
+        for _, n := range names1 {
+            if strings.ToLower(h.HomeTeam) == n {
+                m.HomeTeamID, m.AwayTeamID = team1.ID, team2.ID
+            }
+        }
+        meetings = append(meetings, m)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    rows, err = r.db.Query(`
+        SELECT s.name, m.week, m.kickoff, m.home_team_id, m.away_team_id, m.home_goals, m.away_goals
+        FROM matches m
+        JOIN seasons s ON s.id = m.season_id
+        WHERE (m.home_team_id = $1 AND m.away_team_id = $2)
+           OR (m.home_team_id = $2 AND m.away_team_id = $1)
+        ORDER BY s.id, m.week, m.id
+    `, team1.ID, team2.ID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    for rows.Next() {
+        m := models.Meeting{Source: models.MeetingSourceLeague}
+        var kickoff sql.NullTime
+        if err := rows.Scan(&m.Season, &m.Week, &kickoff,
+            &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals); err != nil {
+            return nil, err
+        }
+        m.PlayedOn = kickoff.Time
+        meetings = append(meetings, m)
+    }
+    return meetings, rows.Err()
+}
+
+// SaveHistory stores imported results in one transaction, skipping those
+// already stored, and returns how many were new.
+func (r *PostgresRepo) SaveHistory(ms []models.HistoricalMatch) (int, error) {
+    added := 0
+    err := r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        for _, m := range ms {
+            var id int
+            err := tx.db.QueryRow(`
+                INSERT INTO historical_matches
+                    (source, competition, season, played_on, home_team, away_team, home_goals, away_goals)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+                ON CONFLICT DO NOTHING
+                RETURNING id
+            `, m.Source, m.Competition, m.Season, nullTime(m.PlayedOn),
+                m.HomeTeam, m.AwayTeam, m.HomeGoals, m.AwayGoals).Scan(&id)
+            if err == sql.ErrNoRows {
+                continue // already imported
+            }
+            if err != nil {
+                return err
+            }
+            added++
+            if m.Stats != nil {
+                if err := tx.saveStats("historical_match_stats", "historical_id", id, *m.Stats); err != nil {
+                    return err
+                }
+            }
+        }
+        return nil
+    })
+    return added, err
+}
+
+// ListHistory returns imported results in date order, only those of team
+// (by name, ignoring case) when it is not empty.
+func (r *PostgresRepo) ListHistory(team string) ([]models.HistoricalMatch, error) {
+    rows, err := r.db.Query(`
+        SELECT `+historyColumns+`
+        FROM historical_matches
+        WHERE $1 = '' OR lower(home_team) = lower($1) OR lower(away_team) = lower($1)
+        ORDER BY played_on NULLS FIRST, id
+    `, team)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var ms []models.HistoricalMatch
+    for rows.Next() {
+        var m models.HistoricalMatch
+        if err := scanHistory(rows, &m); err != nil {
+            return nil, err
+        }
+        ms = append(ms, m)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    ids := make([]int, len(ms))
+    for i, m := range ms {
+        ids[i] = m.ID
+    }
+    stats, err := r.loadStats("historical_match_stats", "historical_id", ids)
+    if err != nil {
+        return nil, err
+    }
+    for i := range ms {
+        ms[i].Stats = stats[ms[i].ID]
+    }
+    return ms, nil
+}
@@ -0,0 +1,160 @@
+package repo
+
+import (
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// jobColumns lists the jobs columns read by scanJob; jobSummaryColumns
+// leaves out the result, which can be large.
+const (
+    jobColumns = `id, kind, status, params, progress, result, error, attempts,
+    created_by, created_at, started_at, finished_at`
+    jobSummaryColumns = `id, kind, status, params, progress, NULL::jsonb, error, attempts,
+    created_by, created_at, started_at, finished_at`
+)
+
+func scanJob(row rowScanner, j *models.Job) error {
+    var params, result []byte
+    var started, finished sql.NullTime
+    err := row.Scan(&j.ID, &j.Kind, &j.Status, &params, &j.Progress, &result, &j.Error, &j.Attempts,
+        &j.CreatedBy, &j.CreatedAt, &started, &finished)
+    if err != nil {
+        return err
+    }
+    j.Params, j.Result = params, result
+    j.StartedAt, j.FinishedAt = started.Time, finished.Time
+    return nil
+}
+
+// CreateJob queues j, recording the repo's actor as its author.
+func (r *PostgresRepo) CreateJob(j models.Job) (models.Job, error) {
+    err := scanJob(r.db.QueryRow(`
+        INSERT INTO jobs (kind, status, params, created_by)
+        VALUES ($1, '`+models.JobQueued+`', $2, $3)
+        RETURNING `+jobColumns+`
+    `, j.Kind, []byte(j.Params), r.actor), &j)
+    return j, err
+}
+
+func (r *PostgresRepo) GetJob(id int) (models.Job, error) {
+    var j models.Job
+    err := scanJob(r.db.QueryRow(`
+        SELECT `+jobColumns+`
+        FROM jobs
+        WHERE id = $1
+    `, id), &j)
+    if err == sql.ErrNoRows {
+        return j, fmt.Errorf("job %d: %w", id, interfaces.ErrNotFound)
+    }
+    return j, err
+}
+
+// ListJobs returns jobs newest first, without their results.
+func (r *PostgresRepo) ListJobs(status string) ([]models.Job, error) {
+    rows, err := r.db.Query(`
+        SELECT `+jobSummaryColumns+`
+        FROM jobs
+        WHERE $1 = '' OR status = $1
+        ORDER BY id DESC
+    `, status)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    jobs := []models.Job{}
+    for rows.Next() {
+        var j models.Job
+        if err := scanJob(rows, &j); err != nil {
+            return nil, err
+        }
+        jobs = append(jobs, j)
+    }
+    return jobs, rows.Err()
+}
+
+// ClaimJob takes the oldest queued job. SKIP LOCKED keeps concurrent
+// workers, in this process or another, from claiming the same one.
+func (r *PostgresRepo) ClaimJob() (models.Job, error) {
+    var j models.Job
+    err := scanJob(r.db.QueryRow(`
+        UPDATE jobs
+        SET status = '`+models.JobRunning+`', attempts = attempts + 1, progress = 0,
+            started_at = now(), heartbeat_at = now()
+        WHERE id = (
+            SELECT id FROM jobs
+            WHERE status = '`+models.JobQueued+`'
+            ORDER BY id
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING `+jobColumns+`
+    `), &j)
+    if err == sql.ErrNoRows {
+        return j, fmt.Errorf("queued job: %w", interfaces.ErrNotFound)
+    }
+    return j, err
+}
+
+func (r *PostgresRepo) UpdateJobProgress(id, attempt int, progress float64) (bool, error) {
+    res, err := r.db.Exec(`
+        UPDATE jobs
+        SET progress = $3, heartbeat_at = now()
+        WHERE id = $1 AND attempts = $2 AND status = '`+models.JobRunning+`'
+    `, id, attempt, progress)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+func (r *PostgresRepo) FinishJob(id, attempt int, status string, result []byte, msg string) error {
+    _, err := r.db.Exec(`
+        UPDATE jobs
+        SET status = $3, result = $4, error = $5, finished_at = now(),
+            progress = CASE WHEN $3 = '`+models.JobSucceeded+`' THEN 1 ELSE progress END
+        WHERE id = $1 AND attempts = $2 AND status = '`+models.JobRunning+`'
+    `, id, attempt, status, result, msg)
+    return err
+}
+
+func (r *PostgresRepo) CancelJob(id int) (bool, error) {
+    res, err := r.db.Exec(`
+        UPDATE jobs
+        SET status = '`+models.JobCancelled+`', finished_at = now()
+        WHERE id = $1 AND status IN ('`+models.JobQueued+`', '`+models.JobRunning+`')
+    `, id)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+func (r *PostgresRepo) RequeueStaleJobs(before time.Time, maxAttempts int) (int, error) {
+    res, err := r.db.Exec(`
+        UPDATE jobs
+        SET status = '`+models.JobQueued+`'
+        WHERE status = '`+models.JobRunning+`' AND heartbeat_at < $1 AND attempts < $2
+    `, before, maxAttempts)
+    if err != nil {
+        return 0, err
+    }
+    requeued, err := res.RowsAffected()
+    if err != nil {
+        return 0, err
+    }
+    _, err = r.db.Exec(`
+        UPDATE jobs
+        SET status = '`+models.JobFailed+`', finished_at = now(),
+            error = format('abandoned after %s attempts', attempts)
+        WHERE status = '`+models.JobRunning+`' AND heartbeat_at < $1 AND attempts >= $2
+    `, before, maxAttempts)
+    return int(requeued), err
+}
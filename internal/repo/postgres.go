@@ -2,20 +2,37 @@ package repo
 
 import (
     "database/sql"
+    "errors"
     "fmt"
 
-    _ "github.com/lib/pq"
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/dates"
     "github.com/musta/insider-league/internal/interfaces"
     "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
     "github.com/musta/insider-league/internal/service"
+    "github.com/musta/insider-league/internal/split"
 )
 
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+    Exec(query string, args ...interface{}) (sql.Result, error)
+    Query(query string, args ...interface{}) (*sql.Rows, error)
+    QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // PostgresRepo implements interfaces.Repository.
 type PostgresRepo struct {
-    db      *sql.DB
+    conn    *sql.DB
+    db      queryer // conn, or the open transaction inside RunInTx
+    inTx    bool
+    actor   string // recorded in match_events
     updater interfaces.TableUpdater
 }
 
+// defaultActor is recorded for changes made without WithActor.
+const defaultActor = "system"
+
 // NewPostgresRepo connects to Postgres and returns a Repository.
 func NewPostgresRepo(dsn string) (interfaces.Repository, error) {
     db, err := sql.Open("postgres", dsn)
@@ -26,17 +43,70 @@ func NewPostgresRepo(dsn string) (interfaces.Repository, error) {
         return nil, err
     }
     return &PostgresRepo{
+        conn:    db,
         db:      db,
+        actor:   defaultActor,
         updater: service.NewTableUpdater(),
     }, nil
 }
 
+// WithActor returns a copy of the repo that records actor in match_events.
+func (r *PostgresRepo) WithActor(actor string) interfaces.Repository {
+    c := *r
+    c.actor = actor
+    return &c
+}
+
+// RunInTx runs fn against a Repository bound to a single transaction,
+// committing when fn returns nil and rolling back otherwise. Nested calls
+// join the outer transaction.
+func (r *PostgresRepo) RunInTx(fn func(interfaces.Repository) error) (err error) {
+    if r.inTx {
+        return fn(r)
+    }
+    tx, err := r.conn.Begin()
+    if err != nil {
+        return err
+    }
+    defer func() {
+        if p := recover(); p != nil {
+            _ = tx.Rollback()
+            panic(p)
+        }
+        if err != nil {
+            _ = tx.Rollback()
+            return
+        }
+        err = tx.Commit()
+    }()
+    return fn(&PostgresRepo{conn: r.conn, db: tx, inTx: true, actor: r.actor, updater: r.updater})
+}
+
+// teamColumns lists the teams columns in the order scanTeam reads them.
+const teamColumns = `id, name, short_name, strength, rating, color, crest_url`
+
+// seasonTeamsSQL selects the current season's teams in teamColumns order:
+// those in season_teams with the season's strengths and ratings, or every
+// team when the season has none there.
+const seasonTeamsSQL = `
+    SELECT t.id, t.name, t.short_name, COALESCE(st.strength, t.strength), COALESCE(st.rating, t.rating),
+        t.color, t.crest_url
+    FROM teams t
+    LEFT JOIN season_teams st ON st.team_id = t.id AND st.season_id = ` + currentSeasonSQL + `
+    WHERE (st.team_id IS NOT NULL
+        OR NOT EXISTS (SELECT 1 FROM season_teams WHERE season_id = ` + currentSeasonSQL + `))`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanTeam(row rowScanner, t *models.Team) error {
+    return row.Scan(&t.ID, &t.Name, &t.ShortName, &t.Strength, &t.Rating, &t.Color, &t.CrestURL)
+}
+
 func (r *PostgresRepo) ListTeams() ([]models.Team, error) {
-    rows, err := r.db.Query(`
-        SELECT id, name, strength
-        FROM teams
-        ORDER BY id
-    `)
+    rows, err := r.db.Query(seasonTeamsSQL + ` ORDER BY t.id`)
     if err != nil {
         return nil, err
     }
@@ -45,7 +115,7 @@ func (r *PostgresRepo) ListTeams() ([]models.Team, error) {
     var teams []models.Team
     for rows.Next() {
         var t models.Team
-        if err := rows.Scan(&t.ID, &t.Name, &t.Strength); err != nil {
+        if err := scanTeam(rows, &t); err != nil {
             return nil, err
         }
         fmt.Printf("[REPO] ListTeams: id=%d, name=%s, strength=%d\n", t.ID, t.Name, t.Strength)
@@ -54,31 +124,182 @@ func (r *PostgresRepo) ListTeams() ([]models.Team, error) {
     return teams, nil
 }
 
-func (r *PostgresRepo) SaveTeam(team models.Team) error {
+func (r *PostgresRepo) SaveTeam(team models.Team) (models.Team, error) {
     fmt.Printf("[REPO] SaveTeam: name=%s, strength=%d\n", team.Name, team.Strength)
     // Always insert with default serial id, ignore provided id
-    _, err := r.db.Exec(`
-        INSERT INTO teams (name, strength)
-        VALUES ($1, $2)
-    `, team.Name, team.Strength)
-    return err
+    err := r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if err := tx.db.QueryRow(`
+            INSERT INTO teams (name, short_name, strength, rating, color, crest_url)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING id
+        `, team.Name, team.ShortName, team.Strength, team.Rating, team.Color, team.CrestURL).Scan(&team.ID); err != nil {
+            return err
+        }
+        // A season with its own team list gets the new team too.
+        _, err := tx.db.Exec(`
+            INSERT INTO season_teams (season_id, team_id, strength, rating)
+            SELECT season_id, $1::int, $2::int, $3::float8
+            FROM season_teams
+            WHERE season_id = `+currentSeasonSQL+`
+            LIMIT 1
+        `, team.ID, team.Strength, team.Rating)
+        return err
+    })
+    return team, translateErr(err)
+}
+
+func (r *PostgresRepo) GetTeam(id int) (models.Team, error) {
+    var t models.Team
+    err := scanTeam(r.db.QueryRow(seasonTeamsSQL+` AND t.id = $1`, id), &t)
+    if err == sql.ErrNoRows {
+        return t, fmt.Errorf("team %d: %w", id, interfaces.ErrNotFound)
+    }
+    return t, err
 }
 
+// UpdateTeam writes the strength and rating to the current season's
+// season_teams row when it has one, and to the shared row otherwise.
+func (r *PostgresRepo) UpdateTeam(team models.Team) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        res, err := tx.db.Exec(`
+            UPDATE season_teams
+            SET strength = $2, rating = $3
+            WHERE season_id = `+currentSeasonSQL+` AND team_id = $1
+        `, team.ID, team.Strength, team.Rating)
+        if err != nil {
+            return translateErr(err)
+        }
+        seasonal, _ := res.RowsAffected()
+        res, err = tx.db.Exec(`
+            UPDATE teams
+            SET name = $2, short_name = $3, color = $4, crest_url = $5,
+                strength = CASE WHEN $8 THEN strength ELSE $6 END,
+                rating = CASE WHEN $8 THEN rating ELSE $7 END
+            WHERE id = $1
+        `, team.ID, team.Name, team.ShortName, team.Color, team.CrestURL, team.Strength, team.Rating, seasonal > 0)
+        if err != nil {
+            return translateErr(err)
+        }
+        if n, _ := res.RowsAffected(); n == 0 {
+            return fmt.Errorf("team %d: %w", team.ID, interfaces.ErrNotFound)
+        }
+        return nil
+    })
+}
+
+func (r *PostgresRepo) DeleteTeam(id int) error {
+    res, err := r.db.Exec(`DELETE FROM teams WHERE id = $1`, id)
+    if err != nil {
+        return translateErr(err)
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("team %d: %w", id, interfaces.ErrNotFound)
+    }
+    return nil
+}
+
+// currentSeasonSQL selects the current season id inside other statements.
+const currentSeasonSQL = `(SELECT id FROM seasons WHERE is_current)`
+
+// seasonLockClass namespaces this app's advisory locks (two-key form).
+const seasonLockClass = 0x4c53 // "LS"
+
 func (r *PostgresRepo) SaveMatch(m models.MatchResult) error {
+    if m.Stats != nil || len(m.Goals) > 0 {
+        return r.RunInTx(func(txRepo interfaces.Repository) error {
+            tx := txRepo.(*PostgresRepo)
+            plain := m
+            plain.Stats, plain.Goals = nil, nil
+            if err := tx.SaveMatch(plain); err != nil {
+                return err
+            }
+            return tx.saveDetails(m)
+        })
+    }
     _, err := r.db.Exec(`
-        INSERT INTO matches (week, home_team_id, away_team_id, home_goals, away_goals)
-        VALUES ($1, $2, $3, $4, $5)
-    `, m.Week, m.HomeTeamID, m.AwayTeamID, m.HomeGoals, m.AwayGoals)
+        WITH ins AS (
+            INSERT INTO matches (season_id, week, home_team_id, away_team_id, home_goals, away_goals, kickoff)
+            VALUES (`+currentSeasonSQL+`, $1, $2, $3, $4, $5, $7)
+            RETURNING id, season_id, week, home_team_id, away_team_id, home_goals, away_goals
+        )
+        INSERT INTO match_events
+            (match_id, season_id, action, week, home_team_id, away_team_id, new_home_goals, new_away_goals, actor)
+        SELECT id, season_id, 'insert', week, home_team_id, away_team_id, home_goals, away_goals, $6
+        FROM ins
+    `, m.Week, m.HomeTeamID, m.AwayTeamID, m.HomeGoals, m.AwayGoals, r.actor, nullTime(m.Kickoff))
+    return translateErr(err)
+}
+
+// LockSeason takes the season's advisory lock for the rest of the current
+// transaction, or fails with interfaces.ErrLocked if someone else holds it.
+func (r *PostgresRepo) LockSeason(season int) error {
+    if !r.inTx {
+        return fmt.Errorf("LockSeason must run inside RunInTx")
+    }
+    var ok bool
+    if err := r.db.QueryRow(`SELECT pg_try_advisory_xact_lock($1, $2)`, seasonLockClass, season).Scan(&ok); err != nil {
+        return err
+    }
+    if !ok {
+        return interfaces.ErrLocked
+    }
+    return nil
+}
+
+// translateErr maps Postgres constraint violations onto interfaces errors.
+func translateErr(err error) error {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        switch pqErr.Code {
+        case "23505": // unique_violation
+            return fmt.Errorf("%w: %s", interfaces.ErrConflict, pqErr.Detail)
+        case "23503": // foreign_key_violation
+            return fmt.Errorf("%w: %s", interfaces.ErrConflict, pqErr.Detail)
+        }
+    }
     return err
 }
 
-// UpdateMatch updates the result of a match by week, home_team_id, and away_team_id.
+// UpdateMatch updates the result of a match by ID, or by week,
+// home_team_id and away_team_id when ID is zero. Its stats and goals are
+// replaced by m's.
 func (r *PostgresRepo) UpdateMatch(m models.MatchResult) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if err := tx.updateScore(m); err != nil {
+            return err
+        }
+        return tx.saveDetails(m)
+    })
+}
+
+// updateScore changes the score of the match UpdateMatch describes.
+func (r *PostgresRepo) updateScore(m models.MatchResult) error {
     res, err := r.db.Exec(`
-        UPDATE matches
-        SET home_goals = $1, away_goals = $2
-        WHERE week = $3 AND home_team_id = $4 AND away_team_id = $5
-    `, m.HomeGoals, m.AwayGoals, m.Week, m.HomeTeamID, m.AwayTeamID)
+        WITH old AS (
+            SELECT id, home_goals, away_goals
+            FROM matches
+            WHERE season_id = `+currentSeasonSQL+`
+              AND CASE WHEN $7 > 0 THEN id = $7
+                       ELSE week = $3 AND home_team_id = $4 AND away_team_id = $5 END
+            FOR UPDATE
+        ), upd AS (
+            UPDATE matches m
+            SET home_goals = $1, away_goals = $2
+            FROM old
+            WHERE m.id = old.id
+            RETURNING m.id, m.season_id, m.week, m.home_team_id, m.away_team_id,
+                      old.home_goals AS old_home, old.away_goals AS old_away, m.home_goals, m.away_goals
+        )
+        INSERT INTO match_events
+            (match_id, season_id, action, week, home_team_id, away_team_id,
+             old_home_goals, old_away_goals, new_home_goals, new_away_goals, actor)
+        SELECT id, season_id, 'update', week, home_team_id, away_team_id,
+               old_home, old_away, home_goals, away_goals, $6
+        FROM upd
+    `, m.HomeGoals, m.AwayGoals, m.Week, m.HomeTeamID, m.AwayTeamID, r.actor, m.ID)
     if err != nil {
         return err
     }
@@ -87,27 +308,69 @@ func (r *PostgresRepo) UpdateMatch(m models.MatchResult) error {
         return err
     }
     if n == 0 {
-        return fmt.Errorf("no such match found to update")
+        return fmt.Errorf("no such match found to update: %w", interfaces.ErrNotFound)
     }
     return nil
 }
 
+// DeleteMatch deletes a result of the current season, and the walkover
+// it was awarded by, if any.
+func (r *PostgresRepo) DeleteMatch(id int) error {
+    res, err := r.db.Exec(`
+        WITH del AS (
+            DELETE FROM matches
+            WHERE season_id = `+currentSeasonSQL+` AND id = $1
+            RETURNING id, season_id, week, home_team_id, away_team_id, home_goals, away_goals
+        ), walkover AS (
+            DELETE FROM adjustments a
+            USING del
+            WHERE a.season_id = del.season_id AND a.kind = 'walkover' AND a.week = del.week
+              AND a.home_team_id = del.home_team_id AND a.away_team_id = del.away_team_id
+        )
+        INSERT INTO match_events
+            (match_id, season_id, action, week, home_team_id, away_team_id, old_home_goals, old_away_goals, actor)
+        SELECT id, season_id, 'delete', week, home_team_id, away_team_id, home_goals, away_goals, $2
+        FROM del
+    `, id, r.actor)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("match %d: %w", id, interfaces.ErrNotFound)
+    }
+    return nil
+}
+
+func (r *PostgresRepo) GetMatch(id int) (models.MatchResult, error) {
+    var m models.MatchResult
+    var kickoff sql.NullTime
+    err := r.db.QueryRow(`
+        SELECT id, week, home_team_id, away_team_id, home_goals, away_goals, kickoff
+        FROM matches
+        WHERE season_id = `+currentSeasonSQL+` AND id = $1
+    `, id).Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals, &kickoff)
+    if err == sql.ErrNoRows {
+        return m, fmt.Errorf("match %d: %w", id, interfaces.ErrNotFound)
+    }
+    if err != nil {
+        return m, err
+    }
+    m.Kickoff = kickoff.Time
+    one := []models.MatchResult{m}
+    err = r.loadDetails(one)
+    return one[0], err
+}
+
 func (r *PostgresRepo) GetTable() (models.LeagueTable, error) {
     teams, err := r.ListTeams()
     if err != nil {
         return nil, err
     }
-    table := make(models.LeagueTable, len(teams))
-    for i, t := range teams {
-        table[i] = models.TeamStanding{
-            TeamID:   t.ID,
-            TeamName: t.Name,
-        }
-    }
 
     rows, err := r.db.Query(`
         SELECT week, home_team_id, away_team_id, home_goals, away_goals
         FROM matches
+        WHERE season_id = `+currentSeasonSQL+`
         ORDER BY week, id
     `)
     if err != nil {
@@ -115,6 +378,8 @@ func (r *PostgresRepo) GetTable() (models.LeagueTable, error) {
     }
     defer rows.Close()
 
+    var matches []models.MatchResult
+    lastWeek := 0
     for rows.Next() {
         var m models.MatchResult
         if err := rows.Scan(
@@ -123,18 +388,41 @@ func (r *PostgresRepo) GetTable() (models.LeagueTable, error) {
         ); err != nil {
             return nil, err
         }
-        table, err = r.updater.Update(table, m)
-        if err != nil {
-            return nil, fmt.Errorf("updating table: %w", err)
-        }
+        matches = append(matches, m)
+        lastWeek = m.Week
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    cfg, err := r.GetSplit()
+    if err != nil {
+        return nil, err
+    }
+    adjs, err := r.ListAdjustments()
+    if err != nil {
+        return nil, err
     }
+    adjs, _ = service.EffectiveAdjustments(adjs, lastWeek)
+    table, err := split.Table(r.updater, teams, matches, adjs, cfg)
+    if err != nil {
+        return nil, err
+    }
+    fixtures, err := r.ListFixtures()
+    if err != nil {
+        return nil, err
+    }
+    service.CountByes(table, fixtures, lastWeek)
+    service.CountPostponed(table, fixtures, lastWeek)
     return table, nil
 }
 
 func (r *PostgresRepo) GetAllMatches() ([]models.MatchResult, error) {
     rows, err := r.db.Query(`
-        SELECT week, home_team_id, away_team_id, home_goals, away_goals
+        SELECT id, week, home_team_id, away_team_id, home_goals, away_goals, kickoff
         FROM matches
+        WHERE season_id = `+currentSeasonSQL+`
     `)
     if err != nil {
         return nil, err
@@ -144,19 +432,110 @@ func (r *PostgresRepo) GetAllMatches() ([]models.MatchResult, error) {
     var all []models.MatchResult
     for rows.Next() {
         var m models.MatchResult
+        var kickoff sql.NullTime
         if err := rows.Scan(
-            &m.Week, &m.HomeTeamID, &m.AwayTeamID,
-            &m.HomeGoals, &m.AwayGoals,
+            &m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID,
+            &m.HomeGoals, &m.AwayGoals, &kickoff,
         ); err != nil {
             return nil, err
         }
+        m.Kickoff = kickoff.Time
         all = append(all, m)
     }
-    return all, nil
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+    return all, r.loadDetails(all)
+}
+
+// ListFixtures returns the current season's stored fixture list, or a
+// double round-robin of the current teams when none has been stored.
+func (r *PostgresRepo) ListFixtures() ([]interfaces.Matchup, error) {
+    rows, err := r.db.Query(`
+        SELECT week, home_team_id, away_team_id, COALESCE(original_week, 0), kickoff
+        FROM fixtures
+        WHERE season_id = ` + currentSeasonSQL + `
+        ORDER BY week, slot
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var fixtures []interfaces.Matchup
+    for rows.Next() {
+        var f interfaces.Matchup
+        var kickoff sql.NullTime
+        if err := rows.Scan(&f.Week, &f.HomeTeamID, &f.AwayTeamID, &f.OriginalWeek, &kickoff); err != nil {
+            return nil, err
+        }
+        f.Kickoff = kickoff.Time
+        fixtures = append(fixtures, f)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+    if len(fixtures) == 0 {
+        teams, err := r.ListTeams()
+        if err != nil {
+            return nil, err
+        }
+        if fixtures, err = (schedule.RoundRobin{Legs: 2}).Generate(teams); err != nil {
+            return nil, err
+        }
+    }
+
+    d, err := r.GetDates()
+    if err != nil {
+        return nil, err
+    }
+    dates.Fill(d, fixtures)
+    return fixtures, nil
+}
+
+// SaveFixtures replaces the current season's fixture list and records the
+// format it was built with. Kickoffs equal to their matchday's under the
+// season's dates are not stored, so they follow later changes to the dates.
+func (r *PostgresRepo) SaveFixtures(format string, fixtures []interfaces.Matchup) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        d, err := tx.GetDates()
+        if err != nil {
+            return err
+        }
+        own := make([]interfaces.Matchup, len(fixtures))
+        copy(own, fixtures)
+        dates.Unfill(d, own)
+        if _, err := tx.db.Exec(`DELETE FROM fixtures WHERE season_id = ` + currentSeasonSQL); err != nil {
+            return err
+        }
+        for i, f := range own {
+            if _, err := tx.db.Exec(`
+                INSERT INTO fixtures (season_id, week, slot, home_team_id, away_team_id, original_week, kickoff)
+                VALUES (`+currentSeasonSQL+`, $1, $2, $3, $4, NULLIF($5, 0), $6)
+            `, f.Week, i, f.HomeTeamID, f.AwayTeamID, f.OriginalWeek, nullTime(f.Kickoff)); err != nil {
+                return translateErr(err)
+            }
+        }
+        _, err = tx.db.Exec(`UPDATE seasons SET schedule = $1 WHERE is_current`, format)
+        return err
+    })
+}
+
+// ScheduleFormat returns the format of the current season's fixture list.
+func (r *PostgresRepo) ScheduleFormat() (string, error) {
+    var format string
+    err := r.db.QueryRow(`SELECT schedule FROM seasons WHERE is_current`).Scan(&format)
+    if err == sql.ErrNoRows {
+        return "", fmt.Errorf("no current season")
+    }
+    return format, err
 }
 
 func (r *PostgresRepo) ListRemainingMatches() ([]interfaces.Matchup, error) {
-    teams, err := r.ListTeams()
+    schedule, err := r.ListFixtures()
     if err != nil {
         return nil, err
     }
@@ -172,9 +551,6 @@ func (r *PostgresRepo) ListRemainingMatches() ([]interfaces.Matchup, error) {
         playedSet[key] = true
     }
 
-    // generate full schedule
-    schedule := generateDoubleRoundRobin(teams)
-
     // filter out already played
     var remaining []interfaces.Matchup
     for _, f := range schedule {
@@ -186,58 +562,318 @@ func (r *PostgresRepo) ListRemainingMatches() ([]interfaces.Matchup, error) {
     return remaining, nil
 }
 
-func generateDoubleRoundRobin(teams []models.Team) []interfaces.Matchup {
-    // extract IDs
-    ids := make([]int, len(teams))
-    for i, t := range teams {
-        ids[i] = t.ID
-    }
-    // if odd, add a dummy bye
-    if len(ids)%2 == 1 {
-        ids = append(ids, 0)
-    }
-    n := len(ids)
-    rounds := n - 1
-
-    build := func(offset int) []interfaces.Matchup {
-        slice := append([]int(nil), ids...)
-        var fixtures []interfaces.Matchup
-        for week := 1; week <= rounds; week++ {
-            for i := 0; i < n/2; i++ {
-                home, away := slice[i], slice[n-1-i]
-                if home != 0 && away != 0 {
-                    fixtures = append(fixtures, interfaces.Matchup{
-                        Week:       week + offset,
-                        HomeTeamID: home,
-                        AwayTeamID: away,
-                    })
+// DeleteMatchesAfter deletes the current season's results after week,
+// and the walkovers among them, and returns how many results went.
+func (r *PostgresRepo) DeleteMatchesAfter(week int) (int, error) {
+    res, err := r.db.Exec(`
+        WITH del AS (
+            DELETE FROM matches
+            WHERE season_id = `+currentSeasonSQL+` AND week > $1
+            RETURNING id, season_id, week, home_team_id, away_team_id, home_goals, away_goals
+        ), walkover AS (
+            DELETE FROM adjustments
+            WHERE season_id = `+currentSeasonSQL+` AND kind = 'walkover' AND week > $1
+        )
+        INSERT INTO match_events
+            (match_id, season_id, action, week, home_team_id, away_team_id, old_home_goals, old_away_goals, actor)
+        SELECT id, season_id, 'delete', week, home_team_id, away_team_id, home_goals, away_goals, $2
+        FROM del
+    `, week, r.actor)
+    if err != nil {
+        return 0, err
+    }
+    n, err := res.RowsAffected()
+    return int(n), err
+}
+
+// ResetMatches deletes the current season's results and adjustments;
+// their stats and goals go with them. Other seasons are left alone.
+func (r *PostgresRepo) ResetMatches() error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if _, err := tx.db.Exec(`
+            INSERT INTO match_events (season_id, action, actor)
+            VALUES (`+currentSeasonSQL+`, 'reset', $1)
+        `, tx.actor); err != nil {
+            return err
+        }
+        if _, err := tx.db.Exec(`DELETE FROM adjustments WHERE season_id = ` + currentSeasonSQL); err != nil {
+            return err
+        }
+        _, err := tx.db.Exec(`DELETE FROM matches WHERE season_id = ` + currentSeasonSQL)
+        return err
+    })
+}
+
+// ResetTeams deletes every team, and with them every season's results,
+// fixtures, adjustments, rosters and divisions. Unlike ResetMatches it is
+// not scoped to the current season: team ids restart, so nothing that
+// refers to the old teams can be kept. Seasons, their configs and
+// snapshots stay.
+func (r *PostgresRepo) ResetTeams() error {
+    fmt.Println("[REPO] TRUNCATE teams RESTART IDENTITY CASCADE")
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        // The cascade wipes matches too.
+        if err := tx.logReset(); err != nil {
+            return err
+        }
+        _, err := tx.db.Exec(`TRUNCATE teams RESTART IDENTITY CASCADE`)
+        return err
+    })
+}
+
+func (r *PostgresRepo) CurrentSeason() (int, error) {
+    var id int
+    err := r.db.QueryRow(`SELECT id FROM seasons WHERE is_current`).Scan(&id)
+    if err == sql.ErrNoRows {
+        return 0, fmt.Errorf("no current season")
+    }
+    return id, err
+}
+
+func (r *PostgresRepo) GetGeneratorConfig(season int) (models.GeneratorConfig, error) {
+    cfg := models.DefaultGeneratorConfig()
+    cfg.Season = season
+    err := r.db.QueryRow(`
+        SELECT avg_goals, home_advantage, dispersion, mapping, strength_scale
+        FROM generator_configs
+        WHERE season_id = $1
+    `, season).Scan(&cfg.AvgGoals, &cfg.HomeAdvantage, &cfg.Dispersion, &cfg.Mapping, &cfg.StrengthScale)
+    if err == sql.ErrNoRows {
+        return cfg, nil
+    }
+    return cfg, err
+}
+
+func (r *PostgresRepo) SaveGeneratorConfig(cfg models.GeneratorConfig) error {
+    _, err := r.db.Exec(`
+        INSERT INTO generator_configs (season_id, avg_goals, home_advantage, dispersion, mapping, strength_scale)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (season_id) DO UPDATE
+        SET avg_goals = EXCLUDED.avg_goals,
+            home_advantage = EXCLUDED.home_advantage,
+            dispersion = EXCLUDED.dispersion,
+            mapping = EXCLUDED.mapping,
+            strength_scale = EXCLUDED.strength_scale
+    `, cfg.Season, cfg.AvgGoals, cfg.HomeAdvantage, cfg.Dispersion, cfg.Mapping, cfg.StrengthScale)
+    return err
+}
+
+func (r *PostgresRepo) CreateSeason(name string) (int, error) {
+    var id int
+    err := r.db.QueryRow(`
+        INSERT INTO seasons (name) VALUES ($1) RETURNING id
+    `, name).Scan(&id)
+    return id, err
+}
+
+func (r *PostgresRepo) SetCurrentSeason(season int) error {
+    // Clear first: the partial unique index allows only one current row.
+    if _, err := r.db.Exec(`UPDATE seasons SET is_current = FALSE WHERE is_current`); err != nil {
+        return err
+    }
+    res, err := r.db.Exec(`UPDATE seasons SET is_current = TRUE WHERE id = $1`, season)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("season %d: %w", season, interfaces.ErrNotFound)
+    }
+    return nil
+}
+
+func (r *PostgresRepo) SetSeasonTeams(teams []models.Team) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if _, err := tx.db.Exec(`DELETE FROM season_teams WHERE season_id = ` + currentSeasonSQL); err != nil {
+            return err
+        }
+        for _, t := range teams {
+            // A team deleted since comes back as it was; existing rows
+            // belong to every season and are left alone.
+            if _, err := tx.db.Exec(`
+                INSERT INTO teams (`+teamColumns+`)
+                VALUES ($1, $2, $3, $4, $5, $6, $7)
+                ON CONFLICT (id) DO NOTHING
+            `, t.ID, t.Name, t.ShortName, t.Strength, t.Rating, t.Color, t.CrestURL); err != nil {
+                return translateErr(err)
+            }
+            if _, err := tx.db.Exec(`
+                INSERT INTO season_teams (season_id, team_id, strength, rating)
+                VALUES (`+currentSeasonSQL+`, $1, $2, $3)
+            `, t.ID, t.Strength, t.Rating); err != nil {
+                return translateErr(err)
+            }
+        }
+        // Keep the serial ahead of the explicit ids.
+        _, err := tx.db.Exec(`SELECT setval(pg_get_serial_sequence('teams', 'id'), GREATEST((SELECT MAX(id) FROM teams), 1))`)
+        return err
+    })
+}
+
+func (r *PostgresRepo) SaveSnapshot(snap models.Snapshot) (models.Snapshot, error) {
+    err := r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if err := tx.db.QueryRow(`
+            INSERT INTO snapshots (name, season_id)
+            VALUES ($1, $2)
+            RETURNING id, created_at
+        `, snap.Name, snap.Season).Scan(&snap.ID, &snap.CreatedAt); err != nil {
+            return err
+        }
+        for _, t := range snap.Teams {
+            if _, err := tx.db.Exec(`
+                INSERT INTO snapshot_teams (snapshot_id, team_id, name, short_name, strength, rating, color, crest_url)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            `, snap.ID, t.ID, t.Name, t.ShortName, t.Strength, t.Rating, t.Color, t.CrestURL); err != nil {
+                return err
+            }
+        }
+        for _, m := range snap.Matches {
+            var key int
+            if err := tx.db.QueryRow(`
+                INSERT INTO snapshot_matches (snapshot_id, week, home_team_id, away_team_id, home_goals, away_goals, kickoff)
+                VALUES ($1, $2, $3, $4, $5, $6, $7)
+                RETURNING id
+            `, snap.ID, m.Week, m.HomeTeamID, m.AwayTeamID, m.HomeGoals, m.AwayGoals, nullTime(m.Kickoff)).Scan(&key); err != nil {
+                return err
+            }
+            if m.Stats != nil {
+                if err := tx.saveStats("snapshot_match_stats", "snapshot_match_id", key, *m.Stats); err != nil {
+                    return err
                 }
             }
-            // rotate but keep first fixed
-            slice = append(slice[:1], append(slice[n-1:], slice[1:n-1]...)...)
+            if err := tx.saveGoals("snapshot_match_goals", "snapshot_match_id", key, m.Goals); err != nil {
+                return err
+            }
+        }
+        for _, a := range snap.Adjustments {
+            if _, err := tx.db.Exec(`
+                INSERT INTO snapshot_adjustments (snapshot_id, kind, team_id, points, week, home_team_id, away_team_id, reason)
+                VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0), $8)
+            `, snap.ID, a.Kind, a.TeamID, a.Points, a.Week, a.HomeTeamID, a.AwayTeamID, a.Reason); err != nil {
+                return err
+            }
         }
-        return fixtures
+        return nil
+    })
+    return snap, err
+}
+
+func (r *PostgresRepo) ListSnapshots() ([]models.Snapshot, error) {
+    rows, err := r.db.Query(`
+        SELECT id, name, season_id, created_at
+        FROM snapshots
+        ORDER BY id
+    `)
+    if err != nil {
+        return nil, err
     }
+    defer rows.Close()
 
-    first := build(0)
-    second := make([]interfaces.Matchup, len(first))
-    for i, f := range first {
-        second[i] = interfaces.Matchup{
-            Week:       f.Week + rounds,
-            HomeTeamID: f.AwayTeamID,
-            AwayTeamID: f.HomeTeamID,
+    var snaps []models.Snapshot
+    for rows.Next() {
+        var s models.Snapshot
+        if err := rows.Scan(&s.ID, &s.Name, &s.Season, &s.CreatedAt); err != nil {
+            return nil, err
         }
+        snaps = append(snaps, s)
     }
-    return append(first, second...)
+    return snaps, rows.Err()
 }
 
-func (r *PostgresRepo) ResetMatches() error {
-    _, err := r.db.Exec(`TRUNCATE matches RESTART IDENTITY`)
-    return err
-}
+func (r *PostgresRepo) GetSnapshot(id int) (models.Snapshot, error) {
+    var snap models.Snapshot
+    err := r.db.QueryRow(`
+        SELECT id, name, season_id, created_at
+        FROM snapshots
+        WHERE id = $1
+    `, id).Scan(&snap.ID, &snap.Name, &snap.Season, &snap.CreatedAt)
+    if err == sql.ErrNoRows {
+        return snap, fmt.Errorf("snapshot %d: %w", id, interfaces.ErrNotFound)
+    }
+    if err != nil {
+        return snap, err
+    }
 
-func (r *PostgresRepo) ResetTeams() error {
-    fmt.Println("[REPO] TRUNCATE teams RESTART IDENTITY CASCADE")
-    _, err := r.db.Exec(`TRUNCATE teams RESTART IDENTITY CASCADE`)
-    return err
+    teamRows, err := r.db.Query(`
+        SELECT team_id, name, short_name, strength, rating, color, crest_url
+        FROM snapshot_teams
+        WHERE snapshot_id = $1
+        ORDER BY team_id
+    `, id)
+    if err != nil {
+        return snap, err
+    }
+    defer teamRows.Close()
+    for teamRows.Next() {
+        var t models.Team
+        if err := scanTeam(teamRows, &t); err != nil {
+            return snap, err
+        }
+        snap.Teams = append(snap.Teams, t)
+    }
+    if err := teamRows.Err(); err != nil {
+        return snap, err
+    }
+
+    matchRows, err := r.db.Query(`
+        SELECT id, week, home_team_id, away_team_id, home_goals, away_goals, kickoff
+        FROM snapshot_matches
+        WHERE snapshot_id = $1
+        ORDER BY week, id
+    `, id)
+    if err != nil {
+        return snap, err
+    }
+    defer matchRows.Close()
+    // The snapshot's own row ids key its details; the matches keep ID 0,
+    // as they are not stored results.
+    var keys []int
+    for matchRows.Next() {
+        var key int
+        var m models.MatchResult
+        var kickoff sql.NullTime
+        if err := matchRows.Scan(&key, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals, &kickoff); err != nil {
+            return snap, err
+        }
+        m.Kickoff = kickoff.Time
+        keys = append(keys, key)
+        snap.Matches = append(snap.Matches, m)
+    }
+    if err := matchRows.Err(); err != nil {
+        return snap, err
+    }
+    stats, err := r.loadStats("snapshot_match_stats", "snapshot_match_id", keys)
+    if err != nil {
+        return snap, err
+    }
+    goals, err := r.loadGoals("snapshot_match_goals", "snapshot_match_id", keys)
+    if err != nil {
+        return snap, err
+    }
+    for i, key := range keys {
+        snap.Matches[i].Stats = stats[key]
+        snap.Matches[i].Goals = goals[key]
+    }
+
+    adjRows, err := r.db.Query(`
+        SELECT kind, team_id, points, week, COALESCE(home_team_id, 0), COALESCE(away_team_id, 0), reason
+        FROM snapshot_adjustments
+        WHERE snapshot_id = $1
+        ORDER BY week
+    `, id)
+    if err != nil {
+        return snap, err
+    }
+    defer adjRows.Close()
+    for adjRows.Next() {
+        var a models.Adjustment
+        if err := adjRows.Scan(&a.Kind, &a.TeamID, &a.Points, &a.Week, &a.HomeTeamID, &a.AwayTeamID, &a.Reason); err != nil {
+            return snap, err
+        }
+        snap.Adjustments = append(snap.Adjustments, a)
+    }
+    return snap, adjRows.Err()
 }
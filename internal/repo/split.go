@@ -0,0 +1,78 @@
+package repo
+
+import (
+    "database/sql"
+
+    "github.com/lib/pq"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// GetSplit returns the current season's split format, or a config without
+// groups when the season does not split.
+func (r *PostgresRepo) GetSplit() (models.SplitConfig, error) {
+    var cfg models.SplitConfig
+    err := r.db.QueryRow(`
+        SELECT after_week, legs, halve_points
+        FROM season_splits
+        WHERE season_id = `+currentSeasonSQL,
+    ).Scan(&cfg.AfterWeek, &cfg.Legs, &cfg.HalvePoints)
+    if err == sql.ErrNoRows {
+        return cfg, nil
+    }
+    if err != nil {
+        return cfg, err
+    }
+
+    rows, err := r.db.Query(`
+        SELECT name, size, team_ids
+        FROM split_groups
+        WHERE season_id = ` + currentSeasonSQL + `
+        ORDER BY position
+    `)
+    if err != nil {
+        return cfg, err
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var g models.SplitGroup
+        var ids pq.Int64Array
+        if err := rows.Scan(&g.Name, &g.Size, &ids); err != nil {
+            return cfg, err
+        }
+        for _, id := range ids {
+            g.TeamIDs = append(g.TeamIDs, int(id))
+        }
+        cfg.Groups = append(cfg.Groups, g)
+    }
+    return cfg, rows.Err()
+}
+
+// SaveSplit replaces the current season's split format, group members
+// included. A config without groups removes it.
+func (r *PostgresRepo) SaveSplit(cfg models.SplitConfig) error {
+    return r.RunInTx(func(txRepo interfaces.Repository) error {
+        tx := txRepo.(*PostgresRepo)
+        if _, err := tx.db.Exec(`DELETE FROM season_splits WHERE season_id = ` + currentSeasonSQL); err != nil {
+            return err
+        }
+        if !cfg.Enabled() {
+            return nil
+        }
+        if _, err := tx.db.Exec(`
+            INSERT INTO season_splits (season_id, after_week, legs, halve_points)
+            VALUES (`+currentSeasonSQL+`, $1, $2, $3)
+        `, cfg.AfterWeek, cfg.Legs, cfg.HalvePoints); err != nil {
+            return err
+        }
+        for i, g := range cfg.Groups {
+            if _, err := tx.db.Exec(`
+                INSERT INTO split_groups (season_id, position, name, size, team_ids)
+                VALUES (`+currentSeasonSQL+`, $1, $2, $3, $4)
+            `, i, g.Name, g.Size, pq.Array(g.TeamIDs)); err != nil {
+                return translateErr(err)
+            }
+        }
+        return nil
+    })
+}
@@ -0,0 +1,74 @@
+package schedule
+
+import (
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Balanced is a double round-robin whose home/away pattern never gives a
+// team three home or three away games in a row.
+//
+// The first leg uses Berger tables with alternating orientation (de Werra's
+// construction), which leaves each team at most one pair of consecutive
+// home or away games. The second leg mirrors the first but starts from its
+// second round, so the turn of the season does not add a third.
+type Balanced struct{}
+
+// Name implements ScheduleGenerator.
+func (Balanced) Name() string { return FormatBalanced }
+
+// Generate implements ScheduleGenerator.
+func (Balanced) Generate(teams []models.Team) ([]interfaces.Matchup, error) {
+    ids := ids(teams)
+    n := len(ids)
+    if n < 2 {
+        return nil, nil
+    }
+    rounds := berger(ids)
+
+    var fixtures []interfaces.Matchup
+    add := func(week int, round [][2]int, swap bool) {
+        for _, p := range round {
+            home, away := p[0], p[1]
+            if home == 0 || away == 0 {
+                continue
+            }
+            if swap {
+                home, away = away, home
+            }
+            fixtures = append(fixtures, interfaces.Matchup{Week: week, HomeTeamID: home, AwayTeamID: away})
+        }
+    }
+    for r, round := range rounds {
+        add(r+1, round, false)
+    }
+    for r := range rounds {
+        add(len(rounds)+r+1, rounds[(r+1)%len(rounds)], true)
+    }
+    return fixtures, nil
+}
+
+// berger returns n-1 rounds of (home, away) pairs. ids[n-1] is the fixed
+// team; in round r it meets ids[r] and is at home in odd rounds. The other
+// pairs are ids[r+k] and ids[r-k] (mod n-1), with home alternating by k.
+func berger(ids []int) [][][2]int {
+    n := len(ids)
+    m := n - 1
+    out := make([][][2]int, m)
+    for r := 0; r < m; r++ {
+        fixed, other := ids[n-1], ids[r]
+        if r%2 == 0 {
+            out[r] = append(out[r], [2]int{other, fixed})
+        } else {
+            out[r] = append(out[r], [2]int{fixed, other})
+        }
+        for k := 1; k < n/2; k++ {
+            x, y := ids[(r+k)%m], ids[((r-k)%m+m)%m]
+            if k%2 == 1 {
+                x, y = y, x
+            }
+            out[r] = append(out[r], [2]int{x, y})
+        }
+    }
+    return out
+}
@@ -0,0 +1,117 @@
+package schedule
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Calendar is a hand-made fixture list, uploaded as CSV rows of
+//
+//	week,home,away
+//
+// where home and away are team ids, names or short names. A first row
+// starting with "week" is treated as a header.
+type Calendar struct {
+    rows []calendarRow
+}
+
+type calendarRow struct {
+    line       int
+    week       int
+    home, away string
+}
+
+// ParseCalendar reads a CSV calendar. Team references are resolved later,
+// by Generate, against the league's teams.
+func ParseCalendar(r io.Reader) (*Calendar, error) {
+    cr := csv.NewReader(r)
+    cr.FieldsPerRecord = 3
+    cr.TrimLeadingSpace = true
+    cr.Comment = '#'
+
+    cal := &Calendar{}
+    for first := true; ; first = false {
+        rec, err := cr.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+        }
+        line, _ := cr.FieldPos(0)
+        if first && strings.EqualFold(strings.TrimSpace(rec[0]), "week") {
+            continue
+        }
+        week, err := strconv.Atoi(strings.TrimSpace(rec[0]))
+        if err != nil || week < 1 {
+            return nil, fmt.Errorf("%w: line %d: week must be a positive integer", ErrInvalidCalendar, line)
+        }
+        cal.rows = append(cal.rows, calendarRow{
+            line: line,
+            week: week,
+            home: strings.TrimSpace(rec[1]),
+            away: strings.TrimSpace(rec[2]),
+        })
+    }
+    if len(cal.rows) == 0 {
+        return nil, fmt.Errorf("%w: no fixtures", ErrInvalidCalendar)
+    }
+    return cal, nil
+}
+
+// Name implements ScheduleGenerator.
+func (c *Calendar) Name() string { return FormatCustom }
+
+// Generate implements ScheduleGenerator. It fails if a row names an
+// unknown team, pits a team against itself or gives a team two games in
+// the same week.
+func (c *Calendar) Generate(teams []models.Team) ([]interfaces.Matchup, error) {
+    lookup := make(map[string]int, 3*len(teams))
+    for _, t := range teams {
+        lookup[strconv.Itoa(t.ID)] = t.ID
+        lookup[strings.ToLower(t.Name)] = t.ID
+        if t.ShortName != "" {
+            lookup[strings.ToLower(t.ShortName)] = t.ID
+        }
+    }
+    resolve := func(row calendarRow, ref string) (int, error) {
+        id, ok := lookup[strings.ToLower(ref)]
+        if !ok {
+            return 0, fmt.Errorf("%w: line %d: unknown team %q", ErrInvalidCalendar, row.line, ref)
+        }
+        return id, nil
+    }
+
+    busy := make(map[[2]int]int) // (week, team) -> line
+    fixtures := make([]interfaces.Matchup, 0, len(c.rows))
+    for _, row := range c.rows {
+        home, err := resolve(row, row.home)
+        if err != nil {
+            return nil, err
+        }
+        away, err := resolve(row, row.away)
+        if err != nil {
+            return nil, err
+        }
+        if home == away {
+            return nil, fmt.Errorf("%w: line %d: team %d cannot play itself", ErrInvalidCalendar, row.line, home)
+        }
+        for _, id := range []int{home, away} {
+            if prev, ok := busy[[2]int{row.week, id}]; ok {
+                return nil, fmt.Errorf("%w: line %d: team %d already plays in week %d (line %d)",
+                    ErrInvalidCalendar, row.line, id, row.week, prev)
+            }
+            busy[[2]int{row.week, id}] = row.line
+        }
+        fixtures = append(fixtures, interfaces.Matchup{Week: row.week, HomeTeamID: home, AwayTeamID: away})
+    }
+    sort.SliceStable(fixtures, func(i, j int) bool { return fixtures[i].Week < fixtures[j].Week })
+    return fixtures, nil
+}
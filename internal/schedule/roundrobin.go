@@ -0,0 +1,78 @@
+package schedule
+
+import (
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// RoundRobin is the circle-method schedule played Legs times. Every other
+// leg swaps home and away, so a double round-robin gives each pairing one
+// home game per side; odd leg counts leave the last leg unbalanced.
+type RoundRobin struct {
+    Legs int
+}
+
+// Name implements ScheduleGenerator.
+func (g RoundRobin) Name() string {
+    switch g.Legs {
+    case 1:
+        return FormatSingle
+    case 2:
+        return FormatDouble
+    case 3:
+        return FormatTriple
+    case 4:
+        return FormatQuadruple
+    }
+    return fmt.Sprintf("round-robin x%d", g.Legs)
+}
+
+// Generate implements ScheduleGenerator.
+func (g RoundRobin) Generate(teams []models.Team) ([]interfaces.Matchup, error) {
+    if g.Legs < 1 {
+        return nil, fmt.Errorf("round-robin needs at least one leg, got %d", g.Legs)
+    }
+    first := circle(ids(teams))
+    rounds := len(ids(teams)) - 1
+
+    var fixtures []interfaces.Matchup
+    for leg := 0; leg < g.Legs; leg++ {
+        for _, f := range first {
+            m := interfaces.Matchup{
+                Week:       f.Week + leg*rounds,
+                HomeTeamID: f.HomeTeamID,
+                AwayTeamID: f.AwayTeamID,
+            }
+            if leg%2 == 1 {
+                m.HomeTeamID, m.AwayTeamID = m.AwayTeamID, m.HomeTeamID
+            }
+            fixtures = append(fixtures, m)
+        }
+    }
+    return fixtures, nil
+}
+
+// circle is one leg of the circle method: the first id stays fixed and the
+// rest rotate one place per round. Pairings with the bye (0) are dropped.
+func circle(ids []int) []interfaces.Matchup {
+    n := len(ids)
+    slice := append([]int(nil), ids...)
+    var fixtures []interfaces.Matchup
+    for week := 1; week <= n-1; week++ {
+        for i := 0; i < n/2; i++ {
+            home, away := slice[i], slice[n-1-i]
+            if home != 0 && away != 0 {
+                fixtures = append(fixtures, interfaces.Matchup{
+                    Week:       week,
+                    HomeTeamID: home,
+                    AwayTeamID: away,
+                })
+            }
+        }
+        // rotate but keep first fixed
+        slice = append(slice[:1], append(slice[n-1:], slice[1:n-1]...)...)
+    }
+    return fixtures
+}
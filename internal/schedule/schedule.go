@@ -0,0 +1,108 @@
+// Package schedule builds the fixture list of a season.
+package schedule
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Formats understood by New. FormatCustom names an uploaded Calendar and
+// cannot be built by New.
+const (
+    FormatSingle    = "single"
+    FormatDouble    = "double"
+    FormatTriple    = "triple"
+    FormatQuadruple = "quadruple"
+    FormatBalanced  = "balanced"
+    FormatCustom    = "custom"
+)
+
+var (
+    // ErrUnknownFormat is returned by New for a name it does not know.
+    ErrUnknownFormat = errors.New("unknown schedule format")
+    // ErrInvalidCalendar is wrapped by every problem found in an
+    // uploaded calendar.
+    ErrInvalidCalendar = errors.New("invalid calendar")
+)
+
+// ScheduleGenerator turns a list of teams into fixtures, ordered by week.
+type ScheduleGenerator interface {
+    // Name is the format stored with the season, e.g. "double".
+    Name() string
+    Generate(teams []models.Team) ([]interfaces.Matchup, error)
+}
+
+// New returns the generator for format. An empty format means FormatDouble.
+func New(format string) (ScheduleGenerator, error) {
+    switch format {
+    case FormatSingle:
+        return RoundRobin{Legs: 1}, nil
+    case "", FormatDouble:
+        return RoundRobin{Legs: 2}, nil
+    case FormatTriple:
+        return RoundRobin{Legs: 3}, nil
+    case FormatQuadruple:
+        return RoundRobin{Legs: 4}, nil
+    case FormatBalanced:
+        return Balanced{}, nil
+    case FormatCustom:
+        return nil, fmt.Errorf("%w: custom calendars must be uploaded", ErrUnknownFormat)
+    }
+    return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// Covers reports whether fixtures involve exactly the given teams, i.e.
+// whether a stored schedule still fits the league.
+func Covers(fixtures []interfaces.Matchup, teams []models.Team) bool {
+    want := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        want[t.ID] = true
+    }
+    seen := make(map[int]bool, len(teams))
+    for _, f := range fixtures {
+        if !want[f.HomeTeamID] || !want[f.AwayTeamID] {
+            return false
+        }
+        seen[f.HomeTeamID], seen[f.AwayTeamID] = true, true
+    }
+    return len(teams) < 2 || len(seen) == len(want)
+}
+
+// Byes returns, for every week of fixtures, the teams that do not play in
+// it. Weeks in which everyone plays are left out. Postponed fixtures count
+// in the week they were first scheduled in, so a postponement is not a bye.
+func Byes(fixtures []interfaces.Matchup, teams []models.Team) map[int][]int {
+    playing := make(map[int]map[int]bool)
+    for _, f := range fixtures {
+        week := f.Round()
+        if playing[week] == nil {
+            playing[week] = make(map[int]bool)
+        }
+        playing[week][f.HomeTeamID] = true
+        playing[week][f.AwayTeamID] = true
+    }
+    byes := make(map[int][]int)
+    for week, ids := range playing {
+        for _, t := range teams {
+            if !ids[t.ID] {
+                byes[week] = append(byes[week], t.ID)
+            }
+        }
+    }
+    return byes
+}
+
+// ids returns the team ids padded with a bye (0) to an even count.
+func ids(teams []models.Team) []int {
+    out := make([]int, len(teams), len(teams)+1)
+    for i, t := range teams {
+        out[i] = t.ID
+    }
+    if len(out)%2 == 1 {
+        out = append(out, 0)
+    }
+    return out
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// MaxAdjustmentPoints caps the size of a single points adjustment.
+const MaxAdjustmentPoints = 100
+
+// ErrInvalidAdjustment is wrapped by every validation failure of
+// AdjustmentService.
+var ErrInvalidAdjustment = errors.New("invalid adjustment")
+
+// AdjustmentService records points deductions, bonuses and walkovers.
+// Walkovers are stored as ordinary 3-0 results, so the table, the audit
+// trail and the remaining fixtures all see them.
+type AdjustmentService struct {
+    repo interfaces.Repository
+}
+
+// NewAdjustmentService returns an AdjustmentService backed by repo.
+func NewAdjustmentService(repo interfaces.Repository) *AdjustmentService {
+    return &AdjustmentService{repo: repo}
+}
+
+// As returns an AdjustmentService that records actor as the author of
+// the results its walkovers write.
+func (s *AdjustmentService) As(actor string) *AdjustmentService {
+    return &AdjustmentService{repo: s.repo.WithActor(actor)}
+}
+
+// List returns the current season's adjustments ordered by week.
+func (s *AdjustmentService) List() ([]models.Adjustment, error) {
+    return s.repo.ListAdjustments()
+}
+
+// Get returns adjustment id.
+func (s *AdjustmentService) Get(id int) (models.Adjustment, error) {
+    return s.repo.GetAdjustment(id)
+}
+
+// Pending returns the points adjustments that only take effect after the
+// latest matchday played so far. The table leaves them out; predictions
+// start from it with them applied.
+func (s *AdjustmentService) Pending() ([]models.Adjustment, error) {
+    return pendingAdjustments(s.repo)
+}
+
+// pendingAdjustments returns repo's adjustments that only take effect
+// after the latest matchday played so far.
+func pendingAdjustments(repo interfaces.Repository) ([]models.Adjustment, error) {
+    adjs, err := repo.ListAdjustments()
+    if err != nil {
+        return nil, err
+    }
+    matches, err := repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+    lastWeek := 0
+    for _, m := range matches {
+        if m.Week > lastWeek {
+            lastWeek = m.Week
+        }
+    }
+    _, pending := EffectiveAdjustments(adjs, lastWeek)
+    return pending, nil
+}
+
+// Create records an adjustment. A walkover writes its 3-0 result; a
+// fixture that already has a result cannot be awarded.
+func (s *AdjustmentService) Create(a models.Adjustment) (models.Adjustment, error) {
+    a.Reason = strings.TrimSpace(a.Reason)
+    if a.Kind == "" {
+        a.Kind = models.AdjustmentPoints
+    }
+    if err := validateAdjustment(a); err != nil {
+        return a, err
+    }
+    var created models.Adjustment
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if _, err := tx.GetTeam(a.TeamID); errors.Is(err, interfaces.ErrNotFound) {
+            return fmt.Errorf("%w: unknown team %d", ErrInvalidAdjustment, a.TeamID)
+        } else if err != nil {
+            return err
+        }
+        if a.Kind == models.AdjustmentWalkover {
+            if err := awardWalkover(tx, a); err != nil {
+                return err
+            }
+        }
+        var err error
+        if created, err = tx.SaveAdjustment(a); err != nil {
+            return err
+        }
+        return syncSplit(tx)
+    })
+    return created, err
+}
+
+// Delete removes an adjustment. Deleting a walkover also removes its
+// result, so the fixture is scheduled again.
+func (s *AdjustmentService) Delete(id int) error {
+    return withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        a, err := tx.GetAdjustment(id)
+        if err != nil {
+            return err
+        }
+        if a.Kind == models.AdjustmentWalkover {
+            // Deleting the result deletes the walkover with it.
+            m, err := findResult(tx, a.Week, a.HomeTeamID, a.AwayTeamID)
+            if err == nil {
+                if err := tx.DeleteMatch(m.ID); err != nil {
+                    return err
+                }
+                return syncSplit(tx)
+            }
+            if !errors.Is(err, interfaces.ErrNotFound) {
+                return err
+            }
+        }
+        if err := tx.DeleteAdjustment(id); err != nil {
+            return err
+        }
+        return syncSplit(tx)
+    })
+}
+
+// validateAdjustment checks the fields that do not need the database.
+func validateAdjustment(a models.Adjustment) error {
+    if a.Reason == "" {
+        return fmt.Errorf("%w: reason is required", ErrInvalidAdjustment)
+    }
+    if a.Week < 0 {
+        return fmt.Errorf("%w: week cannot be negative", ErrInvalidAdjustment)
+    }
+    switch a.Kind {
+    case models.AdjustmentPoints:
+        if a.Points == 0 {
+            return fmt.Errorf("%w: points must not be 0", ErrInvalidAdjustment)
+        }
+        if a.Points > MaxAdjustmentPoints || a.Points < -MaxAdjustmentPoints {
+            return fmt.Errorf("%w: points must be within ±%d", ErrInvalidAdjustment, MaxAdjustmentPoints)
+        }
+        if a.HomeTeamID != 0 || a.AwayTeamID != 0 {
+            return fmt.Errorf("%w: a points adjustment has no fixture", ErrInvalidAdjustment)
+        }
+    case models.AdjustmentWalkover:
+        if a.Points != 0 {
+            return fmt.Errorf("%w: a walkover is scored as a result, not points", ErrInvalidAdjustment)
+        }
+        if a.TeamID != a.HomeTeamID && a.TeamID != a.AwayTeamID {
+            return fmt.Errorf("%w: team_id must be the home or away team", ErrInvalidAdjustment)
+        }
+    default:
+        return fmt.Errorf("%w: kind must be %q or %q", ErrInvalidAdjustment, models.AdjustmentPoints, models.AdjustmentWalkover)
+    }
+    return nil
+}
+
+// awardWalkover writes the walkover's 3-0 result for a scheduled fixture
+// that has neither a result nor a walkover yet. A played score is never
+// overwritten, so withdrawing the walkover loses nothing.
+func awardWalkover(tx interfaces.Repository, a models.Adjustment) error {
+    m := a.Result()
+    f, err := checkScheduled(tx, m)
+    if err != nil {
+        return err
+    }
+    m.Kickoff = f.Kickoff
+    if awarded, err := isWalkover(tx, m); err != nil {
+        return err
+    } else if awarded {
+        return fmt.Errorf("%w: week %d, %d vs %d was already awarded", interfaces.ErrConflict, a.Week, a.HomeTeamID, a.AwayTeamID)
+    }
+    if _, err := findResult(tx, m.Week, m.HomeTeamID, m.AwayTeamID); err == nil {
+        return fmt.Errorf("%w: week %d, %d vs %d already has a result; delete it first", interfaces.ErrConflict, a.Week, a.HomeTeamID, a.AwayTeamID)
+    } else if !errors.Is(err, interfaces.ErrNotFound) {
+        return err
+    }
+    return tx.SaveMatch(m)
+}
+
+// isWalkover reports whether m's fixture was awarded as a walkover.
+func isWalkover(tx interfaces.Repository, m models.MatchResult) (bool, error) {
+    adjs, err := tx.ListAdjustments()
+    if err != nil {
+        return false, err
+    }
+    for _, a := range adjs {
+        if a.Kind == models.AdjustmentWalkover && a.Week == m.Week &&
+            a.HomeTeamID == m.HomeTeamID && a.AwayTeamID == m.AwayTeamID {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+// EffectiveAdjustments splits adjustments into those in effect once
+// matchday throughWeek has been played and those that only count later.
+func EffectiveAdjustments(adjs []models.Adjustment, throughWeek int) (effective, pending []models.Adjustment) {
+    for _, a := range adjs {
+        if a.Week <= throughWeek {
+            effective = append(effective, a)
+        } else {
+            pending = append(pending, a)
+        }
+    }
+    return effective, pending
+}
+
+// ApplyAdjustments applies adjs to table with u.
+func ApplyAdjustments(u interfaces.TableUpdater, table models.LeagueTable, adjs []models.Adjustment) (models.LeagueTable, error) {
+    var err error
+    for _, a := range adjs {
+        if table, err = u.Adjust(table, a); err != nil {
+            return nil, err
+        }
+    }
+    return table, nil
+}
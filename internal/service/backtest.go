@@ -0,0 +1,185 @@
+package service
+
+import (
+    "math"
+    "sort"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Backtest scores a generator's forecasts of played matches. Each match's
+// chances of a home win, a draw and an away win are estimated from sims
+// scores generated for it and compared with its result. One generator from
+// newGen plays every score; one that learns from its own matches, such as
+// Elo, is reset before each, so it does not drift within a forecast.
+// progress, when not nil, is called after every match with how many are
+// done; an error from it stops the backtest.
+func Backtest(newGen interfaces.GeneratorFactory, teams []models.Team, played []models.MatchResult, sims int,
+    progress func(done int) error) (models.BacktestScore, []models.BacktestWeek, error) {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    gen, err := newGen()
+    if err != nil {
+        return models.BacktestScore{}, nil, err
+    }
+    stateful, _ := gen.(interfaces.StatefulGenerator)
+    played = append([]models.MatchResult(nil), played...)
+    sort.SliceStable(played, func(i, j int) bool { return played[i].Week < played[j].Week })
+
+    var total backtestSums
+    byWeek := make(map[int]*backtestSums)
+    var weeks []int
+    for i, m := range played {
+        var counts [3]int
+        for s := 0; s < sims; s++ {
+            if stateful != nil {
+                stateful.Reset()
+            }
+            g, err := gen.Generate(byID[m.HomeTeamID], byID[m.AwayTeamID])
+            if err != nil {
+                return models.BacktestScore{}, nil, err
+            }
+            counts[outcome(g.HomeGoals, g.AwayGoals)]++
+        }
+        // Add-half smoothing keeps an outcome never generated from
+        // costing an infinite log loss.
+        var probs [3]float64
+        for k, c := range counts {
+            probs[k] = (float64(c) + 0.5) / (float64(sims) + 1.5)
+        }
+        actual := outcome(m.HomeGoals, m.AwayGoals)
+        if byWeek[m.Week] == nil {
+            byWeek[m.Week] = &backtestSums{}
+            weeks = append(weeks, m.Week)
+        }
+        total.add(probs, actual)
+        byWeek[m.Week].add(probs, actual)
+        if progress != nil {
+            if err := progress(i + 1); err != nil {
+                return models.BacktestScore{}, nil, err
+            }
+        }
+    }
+
+    out := make([]models.BacktestWeek, len(weeks))
+    for i, w := range weeks {
+        out[i] = models.BacktestWeek{Week: w, BacktestScore: byWeek[w].score()}
+    }
+    return total.score(), out, nil
+}
+
+// outcome is 0 for a home win, 1 for a draw and 2 for an away win.
+func outcome(home, away int) int {
+    switch {
+    case home > away:
+        return 0
+    case home == away:
+        return 1
+    }
+    return 2
+}
+
+// backtestSums adds up the scores of forecasts.
+type backtestSums struct {
+    n                     int
+    brier, logLoss, right float64
+}
+
+func (b *backtestSums) add(probs [3]float64, actual int) {
+    b.n++
+    best := 0
+    for k, p := range probs {
+        o := 0.0
+        if k == actual {
+            o = 1
+        }
+        b.brier += (p - o) * (p - o)
+        if p > probs[best] {
+            best = k
+        }
+    }
+    b.logLoss -= math.Log(probs[actual])
+    if best == actual {
+        b.right++
+    }
+}
+
+func (b *backtestSums) score() models.BacktestScore {
+    if b.n == 0 {
+        return models.BacktestScore{}
+    }
+    n := float64(b.n)
+    return models.BacktestScore{Matches: b.n, Brier: b.brier / n, LogLoss: b.logLoss / n, Accuracy: b.right / n}
+}
+
+// PlaySeasons plays the remaining fixtures n times from table, each season
+// with a generator fresh from newGen, tabulated with u, and returns where
+// each team finished. Points adjustments for weeks still to come must
+// already be in table. progress, when not nil, is called after every
+// season with how many are done; an error from it stops the batch.
+func PlaySeasons(newGen interfaces.GeneratorFactory, u interfaces.TableUpdater, teams []models.Team, table models.LeagueTable,
+    remaining []interfaces.Matchup, n int, progress func(done int) error) ([]models.SeasonBatchTeam, error) {
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    type totals struct {
+        points, position int
+        places           []int
+    }
+    sums := make(map[int]*totals, len(table))
+    for _, row := range table {
+        sums[row.TeamID] = &totals{places: make([]int, len(table))}
+    }
+
+    for s := 0; s < n; s++ {
+        gen, err := newGen()
+        if err != nil {
+            return nil, err
+        }
+        sim := make(models.LeagueTable, len(table))
+        copy(sim, table)
+        for _, f := range remaining {
+            m, err := gen.Generate(byID[f.HomeTeamID], byID[f.AwayTeamID])
+            if err != nil {
+                return nil, err
+            }
+            if sim, err = u.Update(sim, m); err != nil {
+                return nil, err
+            }
+        }
+        sim.Sort()
+        for pos, row := range sim {
+            t := sums[row.TeamID]
+            t.points += row.Points
+            t.position += pos + 1
+            t.places[pos]++
+        }
+        if progress != nil {
+            if err := progress(s + 1); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    out := make([]models.SeasonBatchTeam, 0, len(table))
+    for _, row := range table {
+        t := sums[row.TeamID]
+        bt := models.SeasonBatchTeam{
+            TeamID:      row.TeamID,
+            TeamName:    row.TeamName,
+            AvgPoints:   float64(t.points) / float64(n),
+            AvgPosition: float64(t.position) / float64(n),
+            Positions:   make([]float64, len(t.places)),
+        }
+        for i, c := range t.places {
+            bt.Positions[i] = float64(c) / float64(n)
+        }
+        out = append(out, bt)
+    }
+    sort.SliceStable(out, func(i, j int) bool { return out[i].AvgPosition < out[j].AvgPosition })
+    return out, nil
+}
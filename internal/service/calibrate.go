@@ -0,0 +1,50 @@
+package service
+
+import (
+    "errors"
+    "math"
+
+    "github.com/musta/insider-league/internal/models"
+)
+
+// MinCalibrationMatches is the least history CalibrateGeneratorConfig accepts.
+const MinCalibrationMatches = 10
+
+// ErrNotEnoughMatches is returned when there is too little history to calibrate.
+var ErrNotEnoughMatches = errors.New("not enough matches to calibrate")
+
+// CalibrateGeneratorConfig fits average goals, home advantage and
+// dispersion to the given match history. Mapping, StrengthScale and
+// Season are kept from base.
+func CalibrateGeneratorConfig(base models.GeneratorConfig, matches []models.MatchResult) (models.GeneratorConfig, error) {
+    n := float64(len(matches))
+    if len(matches) < MinCalibrationMatches {
+        return base, ErrNotEnoughMatches
+    }
+
+    var homeSum, awaySum float64
+    for _, m := range matches {
+        homeSum += float64(m.HomeGoals)
+        awaySum += float64(m.AwayGoals)
+    }
+    homeMean, awayMean := homeSum/n, awaySum/n
+    if homeMean == 0 || awayMean == 0 {
+        return base, errors.New("cannot calibrate: one side never scored")
+    }
+
+    var homeVar, awayVar float64
+    for _, m := range matches {
+        homeVar += math.Pow(float64(m.HomeGoals)-homeMean, 2)
+        awayVar += math.Pow(float64(m.AwayGoals)-awayMean, 2)
+    }
+    homeVar /= n - 1
+    awayVar /= n - 1
+
+    cfg := base
+    cfg.AvgGoals = homeMean + awayMean
+    cfg.HomeAdvantage = homeMean / awayMean
+    // Method of moments for var = μ + d·μ², averaged over both sides.
+    d := ((homeVar-homeMean)/(homeMean*homeMean) + (awayVar-awayMean)/(awayMean*awayMean)) / 2
+    cfg.Dispersion = math.Max(0, d)
+    return cfg, nil
+}
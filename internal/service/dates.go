@@ -0,0 +1,71 @@
+package service
+
+import (
+    "bytes"
+
+    "github.com/musta/insider-league/internal/dates"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// DatesService puts the current season's matchdays on the calendar.
+// Unlike the fixture list, the dates can change at any time; results keep
+// the kickoff they were played at.
+type DatesService struct {
+    repo interfaces.Repository
+}
+
+// NewDatesService returns a DatesService backed by repo.
+func NewDatesService(repo interfaces.Repository) *DatesService {
+    return &DatesService{repo: repo}
+}
+
+// Get returns the current season's dates.
+func (s *DatesService) Get() (models.SeasonDates, error) {
+    return s.repo.GetDates()
+}
+
+// Set makes d, with defaults for its unset fields, the season's dates.
+func (s *DatesService) Set(d models.SeasonDates) (models.SeasonDates, error) {
+    d = d.WithDefaults()
+    if err := dates.Validate(d); err != nil {
+        return d, err
+    }
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        return tx.SaveDates(d)
+    })
+    return d, err
+}
+
+// Clear removes the season's dates. Kickoffs set for single fixtures stay.
+func (s *DatesService) Clear() error {
+    return withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        return tx.SaveDates(models.SeasonDates{})
+    })
+}
+
+// TeamCalendar returns the dated fixtures of teamID as an iCalendar feed.
+func (s *DatesService) TeamCalendar(teamID int) ([]byte, error) {
+    if _, err := s.repo.GetTeam(teamID); err != nil {
+        return nil, err
+    }
+    season, err := s.repo.CurrentSeason()
+    if err != nil {
+        return nil, err
+    }
+    teams, err := s.repo.ListTeams()
+    if err != nil {
+        return nil, err
+    }
+    fixtures, err := s.repo.ListFixtures()
+    if err != nil {
+        return nil, err
+    }
+    results, err := s.repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+    var buf bytes.Buffer
+    err = dates.WriteICS(&buf, season, teamID, teams, fixtures, results)
+    return buf.Bytes(), err
+}
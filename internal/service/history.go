@@ -0,0 +1,140 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ErrInvalidHistory is wrapped by every invalid imported result.
+var ErrInvalidHistory = errors.New("invalid history")
+
+// DefaultHistorySource is the Source of imported results that name none.
+const DefaultHistorySource = "import"
+
+// HistoryService answers head-to-head questions from the league's results
+// of every season and from imported history.
+type HistoryService struct {
+    repo interfaces.Repository
+}
+
+// NewHistoryService returns a HistoryService backed by repo.
+func NewHistoryService(repo interfaces.Repository) *HistoryService {
+    return &HistoryService{repo: repo}
+}
+
+// HeadToHead returns every meeting of two teams and their record against
+// each other, counted from team1's side.
+func (s *HistoryService) HeadToHead(team1, team2 int) (models.HeadToHead, error) {
+    if team1 == team2 {
+        return models.HeadToHead{}, fmt.Errorf("%w: a head-to-head needs two different teams", ErrInvalidTeam)
+    }
+    t1, err := s.repo.GetTeam(team1)
+    if err != nil {
+        return models.HeadToHead{}, err
+    }
+    t2, err := s.repo.GetTeam(team2)
+    if err != nil {
+        return models.HeadToHead{}, err
+    }
+    meetings, err := s.repo.ListMeetings(t1, t2)
+    if err != nil {
+        return models.HeadToHead{}, err
+    }
+
+    h := models.HeadToHead{
+        Team1ID: t1.ID, Team1Name: t1.Name,
+        Team2ID: t2.ID, Team2Name: t2.Name,
+        Meetings: meetings,
+    }
+    if h.Meetings == nil {
+        h.Meetings = []models.Meeting{}
+    }
+    for _, m := range meetings {
+        goals1, goals2 := m.HomeGoals, m.AwayGoals
+        if m.HomeTeamID != t1.ID {
+            goals1, goals2 = goals2, goals1
+        }
+        h.Played++
+        h.Team1Goals += goals1
+        h.Team2Goals += goals2
+        switch {
+        case goals1 > goals2:
+            h.Team1Wins++
+        case goals1 < goals2:
+            h.Team2Wins++
+        default:
+            h.Draws++
+        }
+    }
+    return h, nil
+}
+
+// Grid returns the current season's results grid.
+func (s *HistoryService) Grid() (models.ResultsGrid, error) {
+    teams, err := s.repo.ListTeams()
+    if err != nil {
+        return models.ResultsGrid{}, err
+    }
+    matches, err := s.repo.GetAllMatches()
+    if err != nil {
+        return models.ResultsGrid{}, err
+    }
+    sort.SliceStable(matches, func(i, j int) bool { return matches[i].Week < matches[j].Week })
+
+    index := make(map[int]int, len(teams))
+    g := models.ResultsGrid{Teams: teams, Results: make([][][]models.MatchResult, len(teams))}
+    for i, t := range teams {
+        index[t.ID] = i
+        g.Results[i] = make([][]models.MatchResult, len(teams))
+        for j := range g.Results[i] {
+            g.Results[i][j] = []models.MatchResult{}
+        }
+    }
+    for _, m := range matches {
+        home, ok1 := index[m.HomeTeamID]
+        away, ok2 := index[m.AwayTeamID]
+        if ok1 && ok2 {
+            g.Results[home][away] = append(g.Results[home][away], m)
+        }
+    }
+    return g, nil
+}
+
+// Import validates and stores imported results and returns how many were
+// new; results already imported are skipped.
+func (s *HistoryService) Import(ms []models.HistoricalMatch) (int, error) {
+    if len(ms) == 0 {
+        return 0, fmt.Errorf("%w: no matches to import", ErrInvalidHistory)
+    }
+    for i := range ms {
+        m := &ms[i]
+        m.HomeTeam = strings.TrimSpace(m.HomeTeam)
+        m.AwayTeam = strings.TrimSpace(m.AwayTeam)
+        if m.Source == "" {
+            m.Source = DefaultHistorySource
+        }
+        switch {
+        case m.HomeTeam == "" || m.AwayTeam == "":
+            return 0, fmt.Errorf("%w: match %d: home_team and away_team are required", ErrInvalidHistory, i)
+        case strings.EqualFold(m.HomeTeam, m.AwayTeam):
+            return 0, fmt.Errorf("%w: match %d: a team cannot play itself", ErrInvalidHistory, i)
+        case m.HomeGoals < 0 || m.AwayGoals < 0:
+            return 0, fmt.Errorf("%w: match %d: goals must be ≥ 0", ErrInvalidHistory, i)
+        }
+        if err := validateStats(m.Stats); err != nil {
+            return 0, fmt.Errorf("%w: match %d: %v", ErrInvalidHistory, i, err)
+        }
+    }
+    return s.repo.SaveHistory(ms)
+}
+
+// List returns imported results, only those of the named team when team
+// is not empty.
+func (s *HistoryService) List(team string) ([]models.HistoricalMatch, error) {
+    return s.repo.ListHistory(strings.TrimSpace(team))
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+var (
+    // ErrInvalidJob is wrapped by every job that cannot be queued.
+    ErrInvalidJob = errors.New("invalid job")
+    // ErrJobFinished is returned when cancelling a job that already ended.
+    ErrJobFinished = errors.New("job already finished")
+)
+
+const (
+    // DefaultJobWorkers is how many jobs a server runs at once.
+    DefaultJobWorkers = 2
+    // MaxJobAttempts is how many times a job is started before a job whose
+    // server keeps dying under it is failed.
+    MaxJobAttempts = 3
+    // jobPoll is how often idle workers look for jobs queued elsewhere and
+    // for running jobs whose server died.
+    jobPoll = 2 * time.Second
+    // jobHeartbeat is how often a running job proves it is alive;
+    // jobStaleAfter is how long without a heartbeat before it is queued
+    // again.
+    jobHeartbeat  = 10 * time.Second
+    jobStaleAfter = time.Minute
+)
+
+// JobRunner runs one kind of job.
+type JobRunner interface {
+    // Check validates the params of a job before it is queued.
+    Check(params json.RawMessage) error
+    // Run does the work and returns the result to store. It reports how
+    // far along it is, from 0 to 1, through progress, and stops early
+    // with ctx's error once ctx is done.
+    Run(ctx context.Context, params json.RawMessage, progress func(float64)) (interface{}, error)
+}
+
+// JobService queues jobs in the database and runs them on a bounded pool
+// of workers. Any server sharing the database may run a queued job, and
+// a job whose server dies is queued again.
+type JobService struct {
+    repo interfaces.Repository
+    pool *jobPool // shared by As copies
+}
+
+// jobPool is the state the workers share.
+type jobPool struct {
+    workers int
+    runners map[string]JobRunner
+    wake    chan struct{}
+    mu      sync.Mutex
+    cancels map[int]context.CancelFunc // running jobs of this process
+}
+
+// NewJobService returns a JobService backed by repo that runs up to
+// workers jobs at once once started.
+func NewJobService(repo interfaces.Repository, workers int) *JobService {
+    return &JobService{repo: repo, pool: &jobPool{
+        workers: max(workers, 1),
+        runners: make(map[string]JobRunner),
+        wake:    make(chan struct{}, 1),
+        cancels: make(map[int]context.CancelFunc),
+    }}
+}
+
+// As returns a JobService that records actor as the author of the jobs it
+// queues. It shares the workers with s.
+func (s *JobService) As(actor string) *JobService {
+    return &JobService{repo: s.repo.WithActor(actor), pool: s.pool}
+}
+
+// Register makes r run the jobs of kind. It must be called before Start.
+func (s *JobService) Register(kind string, r JobRunner) {
+    s.pool.runners[kind] = r
+}
+
+// Start runs the workers until ctx is done.
+func (s *JobService) Start(ctx context.Context) {
+    go s.reap(ctx)
+    for i := 0; i < s.pool.workers; i++ {
+        go s.work(ctx)
+    }
+}
+
+// Submit validates and queues a job.
+func (s *JobService) Submit(kind string, params json.RawMessage) (models.Job, error) {
+    r, ok := s.pool.runners[kind]
+    if !ok {
+        return models.Job{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidJob, kind)
+    }
+    if len(params) == 0 || string(params) == "null" {
+        params = json.RawMessage(`{}`)
+    }
+    if err := r.Check(params); err != nil {
+        return models.Job{}, fmt.Errorf("%w: %v", ErrInvalidJob, err)
+    }
+    j, err := s.repo.CreateJob(models.Job{Kind: kind, Params: params})
+    if err != nil {
+        return models.Job{}, err
+    }
+    select {
+    case s.pool.wake <- struct{}{}:
+    default:
+    }
+    return j, nil
+}
+
+// Get returns one job with its result.
+func (s *JobService) Get(id int) (models.Job, error) {
+    return s.repo.GetJob(id)
+}
+
+// List returns jobs newest first, only those with the given status when
+// status is not empty.
+func (s *JobService) List(status string) ([]models.Job, error) {
+    switch status {
+    case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobFailed, models.JobCancelled:
+    default:
+        return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidJob, status)
+    }
+    return s.repo.ListJobs(status)
+}
+
+// Cancel cancels a queued or running job. A running job stops at its next
+// progress report, in whichever process runs it.
+func (s *JobService) Cancel(id int) (models.Job, error) {
+    ok, err := s.repo.CancelJob(id)
+    if err != nil {
+        return models.Job{}, err
+    }
+    j, err := s.repo.GetJob(id)
+    if err != nil {
+        return models.Job{}, err
+    }
+    if !ok {
+        return j, fmt.Errorf("%w: job %d is %s", ErrJobFinished, id, j.Status)
+    }
+    s.pool.mu.Lock()
+    if cancel := s.pool.cancels[id]; cancel != nil {
+        cancel()
+    }
+    s.pool.mu.Unlock()
+    return j, nil
+}
+
+// reap queues again the jobs whose server stopped running them.
+func (s *JobService) reap(ctx context.Context) {
+    ticker := time.NewTicker(jobPoll)
+    defer ticker.Stop()
+    for {
+        n, err := s.repo.RequeueStaleJobs(time.Now().Add(-jobStaleAfter), MaxJobAttempts)
+        if err != nil {
+            log.Printf("[JOBS] requeue stale jobs: %v", err)
+        } else if n > 0 {
+            log.Printf("[JOBS] requeued %d stale jobs", n)
+            select {
+            case s.pool.wake <- struct{}{}:
+            default:
+            }
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// work runs queued jobs one at a time until ctx is done.
+func (s *JobService) work(ctx context.Context) {
+    ticker := time.NewTicker(jobPoll)
+    defer ticker.Stop()
+    for {
+        for ctx.Err() == nil && s.runNext(ctx) {
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-s.pool.wake:
+        case <-ticker.C:
+        }
+    }
+}
+
+// runNext claims and runs one job. It reports false when none is queued.
+func (s *JobService) runNext(ctx context.Context) bool {
+    j, err := s.repo.ClaimJob()
+    if errors.Is(err, interfaces.ErrNotFound) {
+        return false
+    }
+    if err != nil {
+        log.Printf("[JOBS] claim job: %v", err)
+        return false
+    }
+    s.run(ctx, j)
+    return true
+}
+
+// run runs a claimed job and stores its outcome.
+func (s *JobService) run(ctx context.Context, j models.Job) {
+    jctx, cancel := context.WithCancel(ctx)
+    s.pool.mu.Lock()
+    s.pool.cancels[j.ID] = cancel
+    s.pool.mu.Unlock()
+    defer func() {
+        s.pool.mu.Lock()
+        delete(s.pool.cancels, j.ID)
+        s.pool.mu.Unlock()
+        cancel()
+    }()
+
+    // report stores the progress, at most every percent or second apart
+    // unless forced, and stops the job once it is no longer running.
+    var mu sync.Mutex
+    var last float64
+    var lastAt time.Time
+    report := func(p float64, force bool) {
+        mu.Lock()
+        defer mu.Unlock()
+        if !force && p-last < 0.01 && time.Since(lastAt) < time.Second {
+            return
+        }
+        last, lastAt = p, time.Now()
+        running, err := s.repo.UpdateJobProgress(j.ID, j.Attempts, p)
+        if err != nil {
+            log.Printf("[JOBS] job %d progress: %v", j.ID, err)
+            return
+        }
+        if !running {
+            cancel()
+        }
+    }
+    go func() {
+        ticker := time.NewTicker(jobHeartbeat)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-jctx.Done():
+                return
+            case <-ticker.C:
+                mu.Lock()
+                p := last
+                mu.Unlock()
+                report(p, true)
+            }
+        }
+    }()
+
+    result, err := s.runJob(jctx, j, func(p float64) { report(p, false) })
+    status, msg := models.JobSucceeded, ""
+    switch {
+    case ctx.Err() != nil:
+        // The server is stopping; the job is queued again once its
+        // heartbeat goes stale.
+        return
+    case jctx.Err() != nil:
+        return // cancelled; CancelJob already stored that
+    case err != nil:
+        status, msg = models.JobFailed, err.Error()
+    }
+    var data []byte
+    if status == models.JobSucceeded {
+        if data, err = json.Marshal(result); err != nil {
+            status, msg, data = models.JobFailed, err.Error(), nil
+        }
+    }
+    if err := s.repo.FinishJob(j.ID, j.Attempts, status, data, msg); err != nil {
+        log.Printf("[JOBS] finish job %d: %v", j.ID, err)
+    }
+}
+
+// runJob runs j with its kind's runner, turning a panic into an error.
+func (s *JobService) runJob(ctx context.Context, j models.Job, progress func(float64)) (result interface{}, err error) {
+    r, ok := s.pool.runners[j.Kind]
+    if !ok {
+        return nil, fmt.Errorf("no runner for %q jobs", j.Kind)
+    }
+    defer func() {
+        if p := recover(); p != nil {
+            err = fmt.Errorf("job panicked: %v", p)
+        }
+    }()
+    return r.Run(ctx, j.Params, progress)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// MaxGoals is the highest score a side may be given by hand.
+const MaxGoals = 20
+
+// Limits on the details of a result.
+const (
+    MaxMinute    = 120 // last minute of extra time
+    MaxAddedTime = 30
+    MaxRedCards  = 5 // a side with fewer than seven players is abandoned
+)
+
+// ErrInvalidMatch is wrapped by every validation failure of MatchService.
+var ErrInvalidMatch = errors.New("invalid match")
+
+// MatchFilter narrows MatchService.List. Zero fields match everything.
+type MatchFilter struct {
+    Week   int
+    TeamID int // home or away
+}
+
+// MatchService creates, edits and deletes results after checking them
+// against the fixture list. Writes take the season lock, so they never
+// interleave with a simulation.
+type MatchService struct {
+    repo interfaces.Repository
+}
+
+// NewMatchService returns a MatchService backed by repo.
+func NewMatchService(repo interfaces.Repository) *MatchService {
+    return &MatchService{repo: repo}
+}
+
+// As returns a MatchService that records actor as the author of its changes.
+func (s *MatchService) As(actor string) *MatchService {
+    return &MatchService{repo: s.repo.WithActor(actor)}
+}
+
+// List returns the current season's results ordered by week and id.
+func (s *MatchService) List(f MatchFilter) ([]models.MatchResult, error) {
+    all, err := s.repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+    out := make([]models.MatchResult, 0, len(all))
+    for _, m := range all {
+        if f.Week != 0 && m.Week != f.Week {
+            continue
+        }
+        if f.TeamID != 0 && m.HomeTeamID != f.TeamID && m.AwayTeamID != f.TeamID {
+            continue
+        }
+        out = append(out, m)
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if out[i].Week != out[j].Week {
+            return out[i].Week < out[j].Week
+        }
+        return out[i].ID < out[j].ID
+    })
+    return out, nil
+}
+
+// Get returns one result.
+func (s *MatchService) Get(id int) (models.MatchResult, error) {
+    return s.repo.GetMatch(id)
+}
+
+// Create records the result of a scheduled, not yet played fixture.
+func (s *MatchService) Create(m models.MatchResult) (models.MatchResult, error) {
+    if err := validateScore(m); err != nil {
+        return m, err
+    }
+    if err := validateDetails(m); err != nil {
+        return m, err
+    }
+    var created models.MatchResult
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        f, err := checkScheduled(tx, m)
+        if err != nil {
+            return err
+        }
+        m.Kickoff = f.Kickoff
+        if err := tx.SaveMatch(m); err != nil {
+            return err
+        }
+        if err := syncSplit(tx); err != nil {
+            return err
+        }
+        created, err = findResult(tx, m.Week, m.HomeTeamID, m.AwayTeamID)
+        return err
+    })
+    return created, err
+}
+
+// Update changes the score of an existing result. The fixture itself
+// (week and teams) cannot be changed; delete and re-create it instead.
+// A walkover's result cannot change either; withdraw the walkover.
+// Stats and goals given replace the stored ones; without them the stored
+// stats stay, and so do the goals unless the score changes.
+func (s *MatchService) Update(m models.MatchResult) (models.MatchResult, error) {
+    if err := validateScore(m); err != nil {
+        return m, err
+    }
+    var updated models.MatchResult
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        var cur models.MatchResult
+        var err error
+        if m.ID != 0 {
+            cur, err = tx.GetMatch(m.ID)
+        } else {
+            cur, err = findResult(tx, m.Week, m.HomeTeamID, m.AwayTeamID)
+        }
+        if err != nil {
+            return err
+        }
+        if (m.Week != 0 && m.Week != cur.Week) ||
+            (m.HomeTeamID != 0 && m.HomeTeamID != cur.HomeTeamID) ||
+            (m.AwayTeamID != 0 && m.AwayTeamID != cur.AwayTeamID) {
+            return fmt.Errorf("%w: week and teams of a result cannot change", ErrInvalidMatch)
+        }
+        if awarded, err := isWalkover(tx, cur); err != nil {
+            return err
+        } else if awarded {
+            return fmt.Errorf("%w: week %d, %d vs %d is a walkover; withdraw it instead", interfaces.ErrConflict, cur.Week, cur.HomeTeamID, cur.AwayTeamID)
+        }
+        if m.Goals == nil && (m.HomeGoals != cur.HomeGoals || m.AwayGoals != cur.AwayGoals) {
+            cur.Goals = nil
+        }
+        cur.HomeGoals, cur.AwayGoals = m.HomeGoals, m.AwayGoals
+        if m.Stats != nil {
+            cur.Stats = m.Stats
+        }
+        if m.Goals != nil {
+            cur.Goals = m.Goals
+        }
+        if err := validateDetails(cur); err != nil {
+            return err
+        }
+        if err := tx.UpdateMatch(cur); err != nil {
+            return err
+        }
+        updated = cur
+        return syncSplit(tx)
+    })
+    return updated, err
+}
+
+// Delete removes a result, and the walkover it was awarded by; its
+// fixture becomes scheduled again.
+func (s *MatchService) Delete(id int) error {
+    return withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := tx.DeleteMatch(id); err != nil {
+            return err
+        }
+        return syncSplit(tx)
+    })
+}
+
+// validateScore rejects negative and implausibly large scores.
+func validateScore(m models.MatchResult) error {
+    if m.HomeGoals < 0 || m.AwayGoals < 0 {
+        return fmt.Errorf("%w: goals cannot be negative", ErrInvalidMatch)
+    }
+    if m.HomeGoals > MaxGoals || m.AwayGoals > MaxGoals {
+        return fmt.Errorf("%w: goals cannot exceed %d", ErrInvalidMatch, MaxGoals)
+    }
+    return nil
+}
+
+// validateDetails checks m's stats, and that its goals add up to its
+// score.
+func validateDetails(m models.MatchResult) error {
+    if err := validateStats(m.Stats); err != nil {
+        return err
+    }
+    if len(m.Goals) == 0 {
+        return nil
+    }
+    if len(m.Goals) != m.HomeGoals+m.AwayGoals {
+        return fmt.Errorf("%w: %d goals listed for a %d-%d result", ErrInvalidMatch, len(m.Goals), m.HomeGoals, m.AwayGoals)
+    }
+    home := 0
+    for i, g := range m.Goals {
+        switch {
+        case g.TeamID != m.HomeTeamID && g.TeamID != m.AwayTeamID:
+            return fmt.Errorf("%w: goal %d: team %d is not playing", ErrInvalidMatch, i+1, g.TeamID)
+        case g.Minute < 1 || g.Minute > MaxMinute:
+            return fmt.Errorf("%w: goal %d: minute must be between 1 and %d", ErrInvalidMatch, i+1, MaxMinute)
+        case g.AddedTime < 0 || g.AddedTime > MaxAddedTime:
+            return fmt.Errorf("%w: goal %d: added_time must be between 0 and %d", ErrInvalidMatch, i+1, MaxAddedTime)
+        case i > 0 && goalBefore(g, m.Goals[i-1]):
+            return fmt.Errorf("%w: goals must be listed in the order they were scored", ErrInvalidMatch)
+        }
+        if g.TeamID == m.HomeTeamID {
+            home++
+        }
+    }
+    if home != m.HomeGoals {
+        return fmt.Errorf("%w: %d home goals listed for a %d-%d result", ErrInvalidMatch, home, m.HomeGoals, m.AwayGoals)
+    }
+    return nil
+}
+
+// goalBefore reports whether a was scored before b.
+func goalBefore(a, b models.Goal) bool {
+    if a.Minute != b.Minute {
+        return a.Minute < b.Minute
+    }
+    return a.AddedTime < b.AddedTime
+}
+
+// validateStats rejects negative and inconsistent stats; nil stats are
+// fine.
+func validateStats(s *models.MatchStats) error {
+    if s == nil {
+        return nil
+    }
+    for _, v := range []int{
+        s.HomeShots, s.AwayShots, s.HomeShotsOnTarget, s.AwayShotsOnTarget,
+        s.HomePossession, s.AwayPossession, s.HomeCorners, s.AwayCorners,
+        s.HomeYellowCards, s.AwayYellowCards, s.HomeRedCards, s.AwayRedCards,
+    } {
+        if v < 0 {
+            return fmt.Errorf("%w: stats cannot be negative", ErrInvalidMatch)
+        }
+    }
+    switch {
+    case s.HomeXG < 0 || s.AwayXG < 0 || s.HomeXG > MaxGoals || s.AwayXG > MaxGoals:
+        return fmt.Errorf("%w: xG must be between 0 and %d", ErrInvalidMatch, MaxGoals)
+    case s.HomeShotsOnTarget > s.HomeShots || s.AwayShotsOnTarget > s.AwayShots:
+        return fmt.Errorf("%w: shots on target cannot exceed shots", ErrInvalidMatch)
+    case s.HomePossession+s.AwayPossession != 0 && s.HomePossession+s.AwayPossession != 100:
+        return fmt.Errorf("%w: possession must add up to 100", ErrInvalidMatch)
+    case s.HomeRedCards > MaxRedCards || s.AwayRedCards > MaxRedCards:
+        return fmt.Errorf("%w: a side cannot have more than %d red cards", ErrInvalidMatch, MaxRedCards)
+    }
+    return nil
+}
+
+// checkScheduled returns m's fixture, or fails unless it is on the
+// schedule.
+func checkScheduled(repo interfaces.Repository, m models.MatchResult) (interfaces.Matchup, error) {
+    fixtures, err := repo.ListFixtures()
+    if err != nil {
+        return interfaces.Matchup{}, err
+    }
+    for _, f := range fixtures {
+        if f.Week == m.Week && f.HomeTeamID == m.HomeTeamID && f.AwayTeamID == m.AwayTeamID {
+            return f, nil
+        }
+    }
+    return interfaces.Matchup{}, fmt.Errorf("%w: week %d, %d vs %d is not on the schedule", ErrInvalidMatch, m.Week, m.HomeTeamID, m.AwayTeamID)
+}
+
+// findResult looks a result up by its fixture.
+func findResult(repo interfaces.Repository, week, homeID, awayID int) (models.MatchResult, error) {
+    all, err := repo.GetAllMatches()
+    if err != nil {
+        return models.MatchResult{}, err
+    }
+    for _, m := range all {
+        if m.Week == week && m.HomeTeamID == homeID && m.AwayTeamID == awayID {
+            return m, nil
+        }
+    }
+    return models.MatchResult{}, fmt.Errorf("week %d, %d vs %d: %w", week, homeID, awayID, interfaces.ErrNotFound)
+}
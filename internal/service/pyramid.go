@@ -0,0 +1,195 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "math/rand"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/pyramid"
+    "github.com/musta/insider-league/internal/schedule"
+)
+
+// ErrSeasonNotFinished is returned when rolling over a season that still
+// has fixtures to play.
+var ErrSeasonNotFinished = errors.New("season not finished")
+
+// PyramidService keeps the league pyramid and plays its seasons. Simulate
+// and Predict play in memory; Rollover ends the stored season, moves teams
+// between divisions and starts the next season.
+type PyramidService struct {
+    repo interfaces.Repository
+}
+
+// NewPyramidService returns a PyramidService backed by repo.
+func NewPyramidService(repo interfaces.Repository) *PyramidService {
+    return &PyramidService{repo: repo}
+}
+
+// Get returns the divisions ordered by level.
+func (s *PyramidService) Get() ([]models.Division, error) {
+    return s.repo.ListDivisions()
+}
+
+// Replace validates divs and stores them as the pyramid.
+func (s *PyramidService) Replace(divs []models.Division) ([]models.Division, error) {
+    err := s.repo.RunInTx(func(tx interfaces.Repository) error {
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return err
+        }
+        if err := pyramid.Validate(divs, teams); err != nil {
+            return err
+        }
+        return tx.ReplaceDivisions(divs)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return s.repo.ListDivisions()
+}
+
+// Simulate plays one season of every division without storing anything.
+func (s *PyramidService) Simulate(gen interfaces.MatchGenerator, rng *rand.Rand) (pyramid.SeasonResult, error) {
+    divs, teams, err := s.load(s.repo)
+    if err != nil {
+        return pyramid.SeasonResult{}, err
+    }
+    return pyramid.PlaySeason(divs, teams, gen, NewTableUpdater(), rng)
+}
+
+// Rollover ends the current season of the pyramid and starts the next.
+// Each division's final table is read from the stored results, the
+// promotion playoffs are played with gen, promoted and relegated teams
+// move, and every team's Elo rating is carried over from the season's
+// results and playoffs. The next season, named name, gets a double
+// round-robin in every division, the pyramid's teams with their new
+// ratings and the current season's GeneratorConfig and dates, and becomes
+// current. It returns the season that ended and the new pyramid.
+func (s *PyramidService) Rollover(name string, gen interfaces.MatchGenerator, rng *rand.Rand) (pyramid.SeasonResult, []models.Division, error) {
+    var res pyramid.SeasonResult
+    var next []models.Division
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        divs, teams, err := s.load(tx)
+        if err != nil {
+            return err
+        }
+        remaining, err := tx.ListRemainingMatches()
+        if err != nil {
+            return err
+        }
+        if len(remaining) > 0 {
+            return fmt.Errorf("%w: %d fixtures left", ErrSeasonNotFinished, len(remaining))
+        }
+        table, err := tx.GetTable()
+        if err != nil {
+            return err
+        }
+        played, err := tx.GetAllMatches()
+        if err != nil {
+            return err
+        }
+        if res, err = pyramid.Finish(divs, teams, table, played, gen, rng); err != nil {
+            return err
+        }
+        if err := tx.ReplaceDivisions(pyramid.Rollover(divs, res)); err != nil {
+            return err
+        }
+        if next, err = tx.ListDivisions(); err != nil {
+            return err
+        }
+
+        inPyramid := make(map[int]bool)
+        for _, d := range divs {
+            for _, id := range d.TeamIDs {
+                inPyramid[id] = true
+            }
+        }
+        var carried []models.Team
+        for _, t := range pyramid.CarryRatings(teams, res) {
+            if inPyramid[t.ID] {
+                carried = append(carried, t)
+            }
+        }
+        fixtures, err := pyramid.Fixtures(next, carried)
+        if err != nil {
+            return err
+        }
+
+        season, err := tx.CurrentSeason()
+        if err != nil {
+            return err
+        }
+        cfg, err := tx.GetGeneratorConfig(season)
+        if err != nil {
+            return err
+        }
+        seasonDates, err := tx.GetDates()
+        if err != nil {
+            return err
+        }
+        if name == "" {
+            name = fmt.Sprintf("Season %d", season+1)
+        }
+        if season, err = tx.CreateSeason(name); err != nil {
+            return err
+        }
+        cfg.Season = season
+        if err := tx.SaveGeneratorConfig(cfg); err != nil {
+            return err
+        }
+        if err := tx.SetCurrentSeason(season); err != nil {
+            return err
+        }
+        // The ratings belong to the new season; the shared team rows, and
+        // so earlier seasons, keep theirs.
+        if err := tx.SetSeasonTeams(carried); err != nil {
+            return err
+        }
+        // Dates first, so SaveFixtures only keeps the fixtures' own kickoffs.
+        if err := tx.SaveDates(seasonDates); err != nil {
+            return err
+        }
+        return tx.SaveFixtures(schedule.FormatCustom, fixtures)
+    })
+    return res, next, err
+}
+
+// Predict returns title, promotion, playoff and relegation odds for the
+// season as it stands: every run starts from the stored table, with the
+// pending points adjustments applied, and plays only the remaining
+// fixtures.
+func (s *PyramidService) Predict(newGen interfaces.GeneratorFactory, sims int, rng *rand.Rand) ([]pyramid.Odds, error) {
+    divs, teams, err := s.load(s.repo)
+    if err != nil {
+        return nil, err
+    }
+    table, err := s.repo.GetTable()
+    if err != nil {
+        return nil, err
+    }
+    remaining, err := s.repo.ListRemainingMatches()
+    if err != nil {
+        return nil, err
+    }
+    pending, err := pendingAdjustments(s.repo)
+    if err != nil {
+        return nil, err
+    }
+    u := NewTableUpdater()
+    if table, err = ApplyAdjustments(u, table, pending); err != nil {
+        return nil, err
+    }
+    return pyramid.Predict(divs, teams, table, remaining, newGen, u, sims, rng)
+}
+
+// load reads the pyramid and the teams.
+func (s *PyramidService) load(repo interfaces.Repository) ([]models.Division, []models.Team, error) {
+    divs, err := repo.ListDivisions()
+    if err != nil {
+        return nil, nil, err
+    }
+    teams, err := repo.ListTeams()
+    return divs, teams, err
+}
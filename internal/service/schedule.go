@@ -0,0 +1,219 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
+    "github.com/musta/insider-league/internal/split"
+)
+
+// ErrInvalidPostponement is wrapped by every rejected ScheduleService.Postpone.
+var ErrInvalidPostponement = errors.New("invalid postponement")
+
+// Schedule is the current season's fixture list, the format it was built
+// with and, for a split season, the split.
+type Schedule struct {
+    Format   string               `json:"format"`
+    Split    *models.SplitConfig  `json:"split,omitempty"`
+    Fixtures []interfaces.Matchup `json:"fixtures"`
+}
+
+// ScheduleService picks how the current season's fixtures are built. The
+// fixture list can only change before the first result is recorded.
+type ScheduleService struct {
+    repo interfaces.Repository
+}
+
+// NewScheduleService returns a ScheduleService backed by repo.
+func NewScheduleService(repo interfaces.Repository) *ScheduleService {
+    return &ScheduleService{repo: repo}
+}
+
+// Get returns the current season's schedule.
+func (s *ScheduleService) Get() (Schedule, error) {
+    format, err := s.repo.ScheduleFormat()
+    if err != nil {
+        return Schedule{}, err
+    }
+    cfg, err := s.repo.GetSplit()
+    if err != nil {
+        return Schedule{}, err
+    }
+    fixtures, err := s.repo.ListFixtures()
+    return withSplit(Schedule{Format: format, Fixtures: fixtures}, cfg), err
+}
+
+// withSplit attaches cfg to sched when the season splits.
+func withSplit(sched Schedule, cfg models.SplitConfig) Schedule {
+    if cfg.Enabled() {
+        sched.Split = &cfg
+    }
+    return sched
+}
+
+// Use rebuilds the fixture list of a season that has not started with gen.
+func (s *ScheduleService) Use(gen schedule.ScheduleGenerator) (Schedule, error) {
+    var sched Schedule
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := checkNotStarted(tx); err != nil {
+            return err
+        }
+        var err error
+        sched, err = buildSchedule(tx, gen)
+        return err
+    })
+    return sched, err
+}
+
+// Postpone moves the unplayed fixture of week between homeID and awayID to
+// toWeek, which must come after both the week it was first scheduled in
+// and the latest played week. A toWeek of 0 adds a catch-up week after the
+// last scheduled one. Neither team may already play in toWeek, and in a
+// season that has not split yet a regular-phase fixture has to stay
+// within the regular phase. A non-zero kickoff fixes the fixture's new
+// date and time; otherwise it follows the season's dates for toWeek.
+func (s *ScheduleService) Postpone(week, homeID, awayID, toWeek int, kickoff time.Time) (Schedule, error) {
+    var sched Schedule
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        fixtures, err := tx.ListFixtures()
+        if err != nil {
+            return err
+        }
+        idx, lastWeek := -1, 0
+        for i, f := range fixtures {
+            if f.Week == week && f.HomeTeamID == homeID && f.AwayTeamID == awayID {
+                idx = i
+            }
+            if f.Week > lastWeek {
+                lastWeek = f.Week
+            }
+        }
+        if idx < 0 {
+            return fmt.Errorf("week %d, %d vs %d is not on the schedule: %w", week, homeID, awayID, interfaces.ErrNotFound)
+        }
+        if _, err := findResult(tx, week, homeID, awayID); err == nil {
+            return fmt.Errorf("%w: week %d, %d vs %d has already been played", interfaces.ErrConflict, week, homeID, awayID)
+        } else if !errors.Is(err, interfaces.ErrNotFound) {
+            return err
+        }
+        if toWeek == 0 {
+            toWeek = lastWeek + 1
+        }
+        f := fixtures[idx]
+        if err := checkPostponement(tx, fixtures, f, toWeek); err != nil {
+            return err
+        }
+
+        f.OriginalWeek = f.Round()
+        f.Week, f.Kickoff = toWeek, kickoff
+        fixtures[idx] = f
+        format, err := tx.ScheduleFormat()
+        if err != nil {
+            return err
+        }
+        if err := tx.SaveFixtures(format, fixtures); err != nil {
+            return err
+        }
+        cfg, err := tx.GetSplit()
+        if err != nil {
+            return err
+        }
+        if fixtures, err = tx.ListFixtures(); err != nil {
+            return err
+        }
+        sched = withSplit(Schedule{Format: format, Fixtures: fixtures}, cfg)
+        return nil
+    })
+    return sched, err
+}
+
+// checkPostponement validates moving fixture f to toWeek.
+func checkPostponement(tx interfaces.Repository, fixtures []interfaces.Matchup, f interfaces.Matchup, toWeek int) error {
+    if toWeek <= f.Round() || toWeek == f.Week {
+        return fmt.Errorf("%w: a fixture first scheduled in week %d can only move to a later week", ErrInvalidPostponement, f.Round())
+    }
+    matches, err := tx.GetAllMatches()
+    if err != nil {
+        return err
+    }
+    for _, m := range matches {
+        if m.Week >= toWeek {
+            return fmt.Errorf("%w: week %d has already been played", ErrInvalidPostponement, toWeek)
+        }
+    }
+    cfg, err := tx.GetSplit()
+    if err != nil {
+        return err
+    }
+    if cfg.Enabled() && !cfg.Done() && toWeek > cfg.AfterWeek {
+        return fmt.Errorf("%w: regular-phase fixtures must be played by week %d", ErrInvalidPostponement, cfg.AfterWeek)
+    }
+    for _, other := range fixtures {
+        if other.Week != toWeek {
+            continue
+        }
+        for _, id := range []int{other.HomeTeamID, other.AwayTeamID} {
+            if id == f.HomeTeamID || id == f.AwayTeamID {
+                return fmt.Errorf("%w: team %d already plays in week %d", interfaces.ErrConflict, id, toWeek)
+            }
+        }
+    }
+    return nil
+}
+
+// buildSchedule generates and stores the fixtures of the current teams.
+func buildSchedule(tx interfaces.Repository, gen schedule.ScheduleGenerator) (Schedule, error) {
+    teams, err := tx.ListTeams()
+    if err != nil {
+        return Schedule{}, err
+    }
+    fixtures, err := gen.Generate(teams)
+    if err != nil {
+        return Schedule{}, err
+    }
+    cfg, err := fitSplit(tx, teams)
+    if err != nil {
+        return Schedule{}, err
+    }
+    fixtures = split.Regular(cfg, fixtures)
+    if err := tx.SaveFixtures(gen.Name(), fixtures); err != nil {
+        return Schedule{}, err
+    }
+    return withSplit(Schedule{Format: gen.Name(), Fixtures: fixtures}, cfg), nil
+}
+
+// reschedule rebuilds the fixture list after teams were added or removed,
+// keeping the season's format. An uploaded calendar keeps its remaining
+// fixtures while it still involves every team; otherwise it is replaced
+// by the default double round-robin.
+func reschedule(tx interfaces.Repository) error {
+    format, err := tx.ScheduleFormat()
+    if err != nil {
+        return err
+    }
+    if format == schedule.FormatCustom {
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return err
+        }
+        fixtures, err := tx.ListFixtures()
+        if err != nil {
+            return err
+        }
+        if schedule.Covers(fixtures, teams) {
+            _, err := fitSplit(tx, teams)
+            return err
+        }
+        format = schedule.FormatDouble
+    }
+    gen, err := schedule.New(format)
+    if err != nil {
+        return err
+    }
+    _, err = buildSchedule(tx, gen)
+    return err
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "sync"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
+)
+
+var (
+    // ErrSeasonFinished is returned when there is no fixture left to play.
+    ErrSeasonFinished = errors.New("no remaining fixtures")
+    // ErrSimulationInProgress is returned when another request is already
+    // simulating a matchday.
+    ErrSimulationInProgress = errors.New("a simulation is already in progress")
+    // ErrNothingToUndo is returned when no matchday has been played yet.
+    ErrNothingToUndo = errors.New("no played matchday to undo")
+)
+
+// SimulationError reports which step of a matchday failed. The whole
+// matchday has been rolled back when it is returned.
+type SimulationError struct {
+    Op         string `json:"op"`
+    Week       int    `json:"week,omitempty"`
+    HomeTeamID int    `json:"home_team_id,omitempty"`
+    AwayTeamID int    `json:"away_team_id,omitempty"`
+    Err        error  `json:"-"`
+}
+
+func (e *SimulationError) Error() string {
+    if e.HomeTeamID != 0 {
+        return fmt.Sprintf("%s (week %d, %d vs %d): %v", e.Op, e.Week, e.HomeTeamID, e.AwayTeamID, e.Err)
+    }
+    if e.Week != 0 {
+        return fmt.Sprintf("%s (week %d): %v", e.Op, e.Week, e.Err)
+    }
+    return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *SimulationError) Unwrap() error { return e.Err }
+
+// SimulationService plays matchdays. Each matchday runs in a single
+// transaction, so either all of its fixtures are saved or none are.
+type SimulationService struct {
+    repo interfaces.Repository
+    mu   *sync.Mutex // held while a matchday is being played; shared by As copies
+}
+
+// NewSimulationService returns a SimulationService backed by repo.
+func NewSimulationService(repo interfaces.Repository) *SimulationService {
+    return &SimulationService{repo: repo, mu: &sync.Mutex{}}
+}
+
+// As returns a SimulationService that records actor as the author of the
+// match changes it makes. It shares the in-progress guard with s.
+func (s *SimulationService) As(actor string) *SimulationService {
+    return &SimulationService{repo: s.repo.WithActor(actor), mu: s.mu}
+}
+
+// NextFixtures returns all fixtures that belong to the next un-played week.
+func NextFixtures(repo interfaces.Repository) ([]interfaces.Matchup, error) {
+    remain, err := repo.ListRemainingMatches()
+    if err != nil || len(remain) == 0 {
+        return nil, err
+    }
+    nextWeek := remain[0].Week
+    var out []interfaces.Matchup
+    for _, f := range remain {
+        if f.Week != nextWeek {
+            break
+        }
+        out = append(out, f)
+    }
+    return out, nil
+}
+
+// SimulateWeek plays the next un-played matchday with gen.
+func (s *SimulationService) SimulateWeek(gen interfaces.MatchGenerator) (models.WeekResult, error) {
+    if !s.mu.TryLock() {
+        return models.WeekResult{}, ErrSimulationInProgress
+    }
+    defer s.mu.Unlock()
+    return s.playWeek(gen)
+}
+
+// SimulateWeeks plays up to n matchdays, stopping early when the season
+// ends. Matchdays played before a failure stay committed and are returned
+// alongside the error.
+func (s *SimulationService) SimulateWeeks(gen interfaces.MatchGenerator, n int) ([]models.WeekResult, error) {
+    return s.SimulateWeeksFunc(gen, n, nil)
+}
+
+// SimulateWeeksFunc is SimulateWeeks calling each, when not nil, with every
+// matchday as soon as it is committed. An error from each stops the run
+// and is returned alongside the matchdays played so far.
+func (s *SimulationService) SimulateWeeksFunc(gen interfaces.MatchGenerator, n int,
+    each func(models.WeekResult) error) ([]models.WeekResult, error) {
+    if !s.mu.TryLock() {
+        return nil, ErrSimulationInProgress
+    }
+    defer s.mu.Unlock()
+
+    var played []models.WeekResult
+    for i := 0; n < 0 || i < n; i++ {
+        wr, err := s.playWeek(gen)
+        if errors.Is(err, ErrSeasonFinished) {
+            break
+        }
+        if err != nil {
+            return played, err
+        }
+        played = append(played, wr)
+        if each != nil {
+            if err := each(wr); err != nil {
+                return played, err
+            }
+        }
+    }
+    return played, nil
+}
+
+// SimulateAll plays every remaining matchday.
+func (s *SimulationService) SimulateAll(gen interfaces.MatchGenerator) ([]models.WeekResult, error) {
+    return s.SimulateWeeks(gen, -1)
+}
+
+// playWeek runs one matchday inside a transaction. The caller holds s.mu.
+func (s *SimulationService) playWeek(gen interfaces.MatchGenerator) (models.WeekResult, error) {
+    var wr models.WeekResult
+    err := s.lockedTx(func(tx interfaces.Repository) error {
+        fixtures, err := NextFixtures(tx)
+        if err != nil {
+            return &SimulationError{Op: "list fixtures", Err: err}
+        }
+        if len(fixtures) == 0 {
+            return ErrSeasonFinished
+        }
+        week := fixtures[0].Week
+
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return &SimulationError{Op: "list teams", Week: week, Err: err}
+        }
+        byID := make(map[int]models.Team, len(teams))
+        for _, t := range teams {
+            byID[t.ID] = t
+        }
+
+        played := make([]models.MatchResult, 0, len(fixtures))
+        var timelines []models.MatchTimeline
+        tg, live := gen.(interfaces.TimelineGenerator)
+        for _, f := range fixtures {
+            home, ok1 := byID[f.HomeTeamID]
+            away, ok2 := byID[f.AwayTeamID]
+            if !ok1 || !ok2 {
+                return &SimulationError{Op: "find teams", Week: week, HomeTeamID: f.HomeTeamID, AwayTeamID: f.AwayTeamID,
+                    Err: errors.New("team not found")}
+            }
+            var m models.MatchResult
+            if live {
+                var events []models.TimelineEvent
+                m, events, err = tg.Play(home, away)
+                timelines = append(timelines, models.MatchTimeline{HomeTeamID: home.ID, AwayTeamID: away.ID, Events: events})
+            } else {
+                m, err = gen.Generate(home, away)
+            }
+            if err != nil {
+                return &SimulationError{Op: "generate", Week: week, HomeTeamID: f.HomeTeamID, AwayTeamID: f.AwayTeamID, Err: err}
+            }
+            m.Week, m.Kickoff = f.Week, f.Kickoff
+            if err := tx.SaveMatch(m); err != nil {
+                return &SimulationError{Op: "save match", Week: week, HomeTeamID: f.HomeTeamID, AwayTeamID: f.AwayTeamID, Err: err}
+            }
+            played = append(played, m)
+        }
+        if err := syncSplit(tx); err != nil {
+            return &SimulationError{Op: "split table", Week: week, Err: err}
+        }
+        // Byes come from the whole schedule, so teams whose fixture of this
+        // week was postponed are not reported as sitting it out.
+        all, err := tx.ListFixtures()
+        if err != nil {
+            return &SimulationError{Op: "list fixtures", Week: week, Err: err}
+        }
+        wr = models.WeekResult{Week: week, Fixtures: played, Byes: schedule.Byes(all, teams)[week], Timelines: timelines}
+        return nil
+    })
+    if err != nil {
+        return models.WeekResult{}, err
+    }
+    return wr, nil
+}
+
+// Rewind deletes every result after week, walkovers included, so those
+// fixtures are scheduled again and the table reflects the season as it
+// stood after that week. It returns the number of results removed.
+func (s *SimulationService) Rewind(week int) (int, error) {
+    if !s.mu.TryLock() {
+        return 0, ErrSimulationInProgress
+    }
+    defer s.mu.Unlock()
+
+    var deleted int
+    err := s.lockedTx(func(tx interfaces.Repository) error {
+        n, err := tx.DeleteMatchesAfter(week)
+        if err != nil {
+            return &SimulationError{Op: "delete matches", Week: week, Err: err}
+        }
+        deleted = n
+        if err := syncSplit(tx); err != nil {
+            return &SimulationError{Op: "split table", Week: week, Err: err}
+        }
+        return nil
+    })
+    return deleted, err
+}
+
+// UndoLastWeek deletes the results of the latest played matchday, its
+// walkovers included, and returns that week's number.
+func (s *SimulationService) UndoLastWeek() (int, error) {
+    if !s.mu.TryLock() {
+        return 0, ErrSimulationInProgress
+    }
+    defer s.mu.Unlock()
+
+    var undone int
+    err := s.lockedTx(func(tx interfaces.Repository) error {
+        played, err := tx.GetAllMatches()
+        if err != nil {
+            return &SimulationError{Op: "list matches", Err: err}
+        }
+        for _, m := range played {
+            if m.Week > undone {
+                undone = m.Week
+            }
+        }
+        if undone == 0 {
+            return ErrNothingToUndo
+        }
+        if _, err := tx.DeleteMatchesAfter(undone - 1); err != nil {
+            return &SimulationError{Op: "delete matches", Week: undone, Err: err}
+        }
+        if err := syncSplit(tx); err != nil {
+            return &SimulationError{Op: "split table", Week: undone, Err: err}
+        }
+        return nil
+    })
+    return undone, err
+}
+
+// lockedTx runs fn in a transaction holding the current season's lock.
+func (s *SimulationService) lockedTx(fn func(tx interfaces.Repository) error) error {
+    return withSeasonLock(s.repo, fn)
+}
+
+// withSeasonLock runs fn in a transaction holding the current season's
+// lock, so two servers can never work on the same season at once.
+func withSeasonLock(repo interfaces.Repository, fn func(tx interfaces.Repository) error) error {
+    return repo.RunInTx(func(tx interfaces.Repository) error {
+        season, err := tx.CurrentSeason()
+        if err != nil {
+            return &SimulationError{Op: "current season", Err: err}
+        }
+        if err := tx.LockSeason(season); err != nil {
+            if errors.Is(err, interfaces.ErrLocked) {
+                return ErrSimulationInProgress
+            }
+            return &SimulationError{Op: "lock season", Err: err}
+        }
+        return fn(tx)
+    })
+}
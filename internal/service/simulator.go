@@ -1,7 +1,6 @@
 package service
 
 import (
-    "math"
     "math/rand"
     "time"
 
@@ -13,24 +12,30 @@ import (
 // using a Poisson distribution for goal scoring.
 type PoissonGenerator struct {
     rnd *rand.Rand
+    cfg models.GeneratorConfig
 }
 
 // NewPoissonGenerator returns a ready-to-use MatchGenerator.
 func NewPoissonGenerator() interfaces.MatchGenerator {
+    return NewConfiguredPoissonGenerator(models.DefaultGeneratorConfig())
+}
+
+// NewConfiguredPoissonGenerator returns a MatchGenerator that draws goals
+// from the given scoring environment.
+func NewConfiguredPoissonGenerator(cfg models.GeneratorConfig) interfaces.MatchGenerator {
     return &PoissonGenerator{
         rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+        cfg: cfg,
     }
 }
 
 // Generate simulates a match between home and away teams.
 func (g *PoissonGenerator) Generate(home, away models.Team) (models.MatchResult, error) {
-    // Calculate expected goals (λ) proportional to strengths
-    total := float64(home.Strength + away.Strength)
-    homeLambda := float64(home.Strength) / total * 3.0
-    awayLambda := float64(away.Strength) / total * 2.5
+    // Calculate expected goals (λ) from strengths
+    homeLambda, awayLambda := g.cfg.Lambdas(home.Strength, away.Strength)
 
-    homeGoals := samplePoisson(homeLambda, g.rnd)
-    awayGoals := samplePoisson(awayLambda, g.rnd)
+    homeGoals := g.cfg.SampleGoals(homeLambda, g.rnd)
+    awayGoals := g.cfg.SampleGoals(awayLambda, g.rnd)
 
     return models.MatchResult{
         Week:       0, // set this when you schedule the match
@@ -40,14 +45,3 @@ func (g *PoissonGenerator) Generate(home, away models.Team) (models.MatchResult,
         AwayGoals:  awayGoals,
     }, nil
 }
-
-// samplePoisson draws a random integer from a Poisson distribution.
-func samplePoisson(lambda float64, rnd *rand.Rand) int {
-    L := math.Exp(-lambda)
-    k, p := 0, 1.0
-    for p > L {
-        k++
-        p *= rnd.Float64()
-    }
-    return k - 1
-}
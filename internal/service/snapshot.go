@@ -0,0 +1,213 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/split"
+)
+
+// SnapshotService saves, restores and forks season snapshots.
+type SnapshotService struct {
+    repo interfaces.Repository
+}
+
+// NewSnapshotService returns a SnapshotService backed by repo.
+func NewSnapshotService(repo interfaces.Repository) *SnapshotService {
+    return &SnapshotService{repo: repo}
+}
+
+// As returns a SnapshotService that records actor as the author of the
+// match changes made by Restore and Fork.
+func (s *SnapshotService) As(actor string) *SnapshotService {
+    return &SnapshotService{repo: s.repo.WithActor(actor)}
+}
+
+// Create stores the current season's teams, results and adjustments under
+// name.
+func (s *SnapshotService) Create(name string) (models.Snapshot, error) {
+    if name == "" {
+        return models.Snapshot{}, errors.New("snapshot name required")
+    }
+    var snap models.Snapshot
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        season, err := tx.CurrentSeason()
+        if err != nil {
+            return err
+        }
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return err
+        }
+        matches, err := tx.GetAllMatches()
+        if err != nil {
+            return err
+        }
+        adjs, err := tx.ListAdjustments()
+        if err != nil {
+            return err
+        }
+        snap, err = tx.SaveSnapshot(models.Snapshot{
+            Name:        name,
+            Season:      season,
+            Teams:       teams,
+            Matches:     matches,
+            Adjustments: adjs,
+        })
+        return err
+    })
+    return snap, err
+}
+
+// List returns every stored snapshot without teams, matches or
+// adjustments.
+func (s *SnapshotService) List() ([]models.Snapshot, error) {
+    return s.repo.ListSnapshots()
+}
+
+// Restore replaces the current season's teams, results and adjustments
+// with those of snapshot id.
+func (s *SnapshotService) Restore(id int) (models.Snapshot, error) {
+    snap, err := s.repo.GetSnapshot(id)
+    if err != nil {
+        return snap, err
+    }
+    err = withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        return load(tx, snap)
+    })
+    return snap, err
+}
+
+// Fork starts a new season from snapshot id and makes it current, leaving
+// the season the snapshot was taken from untouched. The new season copies
+// that season's GeneratorConfig, fixture list, dates and split format, and
+// gets the snapshot's teams, results and adjustments. It returns the new
+// season id.
+func (s *SnapshotService) Fork(id int, name string) (int, error) {
+    snap, err := s.repo.GetSnapshot(id)
+    if err != nil {
+        return 0, err
+    }
+    if name == "" {
+        name = fmt.Sprintf("Fork of %s", snap.Name)
+    }
+    var season int
+    err = withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        // The repository reads the current season, so make the snapshot's
+        // season current while its setup is read. The new season replaces
+        // it below, and a failure rolls both back.
+        if err := tx.SetCurrentSeason(snap.Season); err != nil {
+            return err
+        }
+        cfg, err := tx.GetGeneratorConfig(snap.Season)
+        if err != nil {
+            return err
+        }
+        format, err := tx.ScheduleFormat()
+        if err != nil {
+            return err
+        }
+        fixtures, err := tx.ListFixtures()
+        if err != nil {
+            return err
+        }
+        splitCfg, err := tx.GetSplit()
+        if err != nil {
+            return err
+        }
+        for i := range splitCfg.Groups {
+            splitCfg.Groups[i].TeamIDs = nil
+        }
+        seasonDates, err := tx.GetDates()
+        if err != nil {
+            return err
+        }
+        if season, err = tx.CreateSeason(name); err != nil {
+            return err
+        }
+        cfg.Season = season
+        if err := tx.SaveGeneratorConfig(cfg); err != nil {
+            return err
+        }
+        if err := tx.SetCurrentSeason(season); err != nil {
+            return err
+        }
+        if err := tx.SaveSplit(splitCfg); err != nil {
+            return err
+        }
+        // Dates first, so SaveFixtures only keeps the fixtures' own kickoffs.
+        if err := tx.SaveDates(seasonDates); err != nil {
+            return err
+        }
+        if err := tx.SaveFixtures(format, split.Regular(splitCfg, fixtures)); err != nil {
+            return err
+        }
+        return load(tx, snap)
+    })
+    return season, err
+}
+
+// load replaces the current season's teams, results and adjustments with
+// snap's. The teams' strengths and ratings are stored for the season
+// alone, so other seasons keep theirs. Results come back with their
+// kickoffs, stats and goals. The fixture list is rebuilt if the
+// snapshot's teams differ from the season's.
+func load(tx interfaces.Repository, snap models.Snapshot) error {
+    if _, err := tx.DeleteMatchesAfter(0); err != nil {
+        return err
+    }
+    adjs, err := tx.ListAdjustments()
+    if err != nil {
+        return err
+    }
+    for _, a := range adjs {
+        if err := tx.DeleteAdjustment(a.ID); err != nil {
+            return err
+        }
+    }
+    if err := syncSplit(tx); err != nil {
+        return err
+    }
+    before, err := tx.ListTeams()
+    if err != nil {
+        return err
+    }
+    if err := tx.SetSeasonTeams(snap.Teams); err != nil {
+        return err
+    }
+    if !sameTeams(before, snap.Teams) {
+        if err := reschedule(tx); err != nil {
+            return err
+        }
+    }
+    for _, m := range snap.Matches {
+        if err := tx.SaveMatch(m); err != nil {
+            return err
+        }
+    }
+    for _, a := range snap.Adjustments {
+        if _, err := tx.SaveAdjustment(a); err != nil {
+            return err
+        }
+    }
+    return syncSplit(tx)
+}
+
+// sameTeams reports whether a and b hold the same team ids.
+func sameTeams(a, b []models.Team) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    ids := make(map[int]bool, len(a))
+    for _, t := range a {
+        ids[t.ID] = true
+    }
+    for _, t := range b {
+        if !ids[t.ID] {
+            return false
+        }
+    }
+    return true
+}
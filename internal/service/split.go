@@ -0,0 +1,199 @@
+package service
+
+import (
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
+    "github.com/musta/insider-league/internal/split"
+)
+
+// SplitService sets the current season's split format. Like the fixture
+// list, it can only change before the first result is recorded.
+type SplitService struct {
+    repo interfaces.Repository
+}
+
+// NewSplitService returns a SplitService backed by repo.
+func NewSplitService(repo interfaces.Repository) *SplitService {
+    return &SplitService{repo: repo}
+}
+
+// Get returns the current season's split format.
+func (s *SplitService) Get() (models.SplitConfig, error) {
+    return s.repo.GetSplit()
+}
+
+// Set makes cfg the split format of a season that has not started. An
+// AfterWeek of 0 splits after the last week of the current schedule.
+// Fixtures past AfterWeek are dropped; the group fixtures are drawn up
+// once the regular phase has been played.
+func (s *SplitService) Set(cfg models.SplitConfig) (models.SplitConfig, error) {
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := checkNotStarted(tx); err != nil {
+            return err
+        }
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return err
+        }
+        format, fixtures, err := fullSchedule(tx, teams)
+        if err != nil {
+            return err
+        }
+        lastWeek := 0
+        for _, f := range fixtures {
+            if f.Week > lastWeek {
+                lastWeek = f.Week
+            }
+        }
+        if cfg.AfterWeek == 0 {
+            cfg.AfterWeek = lastWeek
+        }
+        if err := split.Validate(cfg, len(teams), lastWeek); err != nil {
+            return err
+        }
+        for i := range cfg.Groups {
+            cfg.Groups[i].TeamIDs = nil
+        }
+        if err := tx.SaveSplit(cfg); err != nil {
+            return err
+        }
+        return tx.SaveFixtures(format, split.Regular(cfg, fixtures))
+    })
+    return cfg, err
+}
+
+// Clear turns a season that has not started back into a plain league and
+// restores its full schedule. An uploaded calendar keeps only the
+// fixtures of its regular phase.
+func (s *SplitService) Clear() error {
+    return withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := checkNotStarted(tx); err != nil {
+            return err
+        }
+        if err := tx.SaveSplit(models.SplitConfig{}); err != nil {
+            return err
+        }
+        teams, err := tx.ListTeams()
+        if err != nil {
+            return err
+        }
+        format, fixtures, err := fullSchedule(tx, teams)
+        if err != nil {
+            return err
+        }
+        return tx.SaveFixtures(format, fixtures)
+    })
+}
+
+// fullSchedule returns the season's format and its fixtures before any
+// split trimmed them: regenerated for built-in formats, as stored for an
+// uploaded calendar.
+func fullSchedule(tx interfaces.Repository, teams []models.Team) (string, []interfaces.Matchup, error) {
+    format, err := tx.ScheduleFormat()
+    if err != nil {
+        return "", nil, err
+    }
+    if format == schedule.FormatCustom {
+        fixtures, err := tx.ListFixtures()
+        return format, fixtures, err
+    }
+    gen, err := schedule.New(format)
+    if err != nil {
+        return "", nil, err
+    }
+    fixtures, err := gen.Generate(teams)
+    return format, fixtures, err
+}
+
+// fitSplit returns the season's split format, first dropping it when its
+// groups no longer add up to the league's teams.
+func fitSplit(tx interfaces.Repository, teams []models.Team) (models.SplitConfig, error) {
+    cfg, err := tx.GetSplit()
+    if err != nil || !cfg.Enabled() || split.Fits(cfg, len(teams)) {
+        return cfg, err
+    }
+    return models.SplitConfig{}, tx.SaveSplit(models.SplitConfig{})
+}
+
+// syncSplit keeps a split season's groups in step with its results. The
+// table is split, and the group fixtures drawn up, as soon as the last
+// regular-phase fixture has a result; until the first group fixture is
+// played the groups follow any change to the regular-phase results, after
+// that they are fixed.
+func syncSplit(tx interfaces.Repository) error {
+    cfg, err := tx.GetSplit()
+    if err != nil || !cfg.Enabled() {
+        return err
+    }
+    fixtures, err := tx.ListFixtures()
+    if err != nil {
+        return err
+    }
+    matches, err := tx.GetAllMatches()
+    if err != nil {
+        return err
+    }
+    var results []models.MatchResult
+    played := make(map[interfaces.Matchup]bool, len(matches))
+    for _, m := range matches {
+        if m.Week > cfg.AfterWeek {
+            if cfg.Done() {
+                return nil
+            }
+            continue
+        }
+        results = append(results, m)
+        played[interfaces.Matchup{Week: m.Week, HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID}] = true
+    }
+
+    regular := split.Regular(cfg, fixtures)
+    complete, lastWeek := len(regular) > 0, 0
+    for _, f := range regular {
+        complete = complete && played[interfaces.Matchup{Week: f.Week, HomeTeamID: f.HomeTeamID, AwayTeamID: f.AwayTeamID}]
+        if f.Week > lastWeek {
+            lastWeek = f.Week
+        }
+    }
+    if !complete && !cfg.Done() {
+        return nil
+    }
+    format, err := tx.ScheduleFormat()
+    if err != nil {
+        return err
+    }
+    for i := range cfg.Groups {
+        cfg.Groups[i].TeamIDs = nil
+    }
+    if !complete {
+        if err := tx.SaveSplit(cfg); err != nil {
+            return err
+        }
+        return tx.SaveFixtures(format, regular)
+    }
+
+    teams, err := tx.ListTeams()
+    if err != nil {
+        return err
+    }
+    adjs, err := tx.ListAdjustments()
+    if err != nil {
+        return err
+    }
+    adjs, _ = EffectiveAdjustments(adjs, cfg.AfterWeek)
+    table, err := split.Table(NewTableUpdater(), teams, results, adjs, cfg)
+    if err != nil {
+        return err
+    }
+    cfg = split.Assign(cfg, table)
+    groups, err := split.Fixtures(cfg, lastWeek+1)
+    if err != nil {
+        return fmt.Errorf("drawing up the split: %w", err)
+    }
+    if err := tx.SaveSplit(cfg); err != nil {
+        return err
+    }
+    return tx.SaveFixtures(format, append(regular, groups...))
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+    "sort"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// DefaultFormGames is how many games form covers unless asked otherwise.
+const DefaultFormGames = 5
+
+// StatsService computes form, streaks and trends from the current
+// season's results.
+type StatsService struct {
+    repo interfaces.Repository
+}
+
+// NewStatsService returns a StatsService backed by repo.
+func NewStatsService(repo interfaces.Repository) *StatsService {
+    return &StatsService{repo: repo}
+}
+
+// Team returns the statistics of team id with form over its last
+// formGames games, and its timeline.
+func (s *StatsService) Team(id, formGames int) (models.TeamStats, error) {
+    team, err := s.repo.GetTeam(id)
+    if err != nil {
+        return models.TeamStats{}, err
+    }
+    matches, err := s.matches()
+    if err != nil {
+        return models.TeamStats{}, err
+    }
+    st := teamStats(team.ID, team.Name, matches, formGames)
+    st.Timeline, err = s.timeline(team.ID, matches)
+    return st, err
+}
+
+// League returns the season's totals and every team's statistics, with
+// form over the last formGames games, in table order.
+func (s *StatsService) League(formGames int) (models.LeagueStats, error) {
+    table, err := s.repo.GetTable()
+    if err != nil {
+        return models.LeagueStats{}, err
+    }
+    matches, err := s.matches()
+    if err != nil {
+        return models.LeagueStats{}, err
+    }
+    // A split table comes ordered group by group already.
+    if len(table) > 0 && table[0].Group == "" {
+        table.Sort()
+    }
+
+    ls := models.LeagueStats{Teams: make([]models.TeamStats, 0, len(table))}
+    for _, row := range table {
+        ls.Teams = append(ls.Teams, teamStats(row.TeamID, row.TeamName, matches, formGames))
+    }
+    for i, m := range matches {
+        ls.Played++
+        ls.Goals += m.HomeGoals + m.AwayGoals
+        switch {
+        case m.HomeGoals > m.AwayGoals:
+            ls.HomeWins++
+        case m.HomeGoals < m.AwayGoals:
+            ls.AwayWins++
+        default:
+            ls.Draws++
+        }
+        if m.HomeGoals == 0 {
+            ls.CleanSheets++
+        }
+        if m.AwayGoals == 0 {
+            ls.CleanSheets++
+        }
+        if m.HomeGoals != m.AwayGoals && (ls.BiggestWin == nil || biggerWin(m, *ls.BiggestWin)) {
+            ls.BiggestWin = &matches[i]
+        }
+        if ls.HighestScoring == nil || m.HomeGoals+m.AwayGoals > ls.HighestScoring.HomeGoals+ls.HighestScoring.AwayGoals {
+            ls.HighestScoring = &matches[i]
+        }
+    }
+    if ls.Played > 0 {
+        ls.GoalsPerGame = float64(ls.Goals) / float64(ls.Played)
+    }
+    return ls, nil
+}
+
+// matches returns the season's results in the order they were played.
+func (s *StatsService) matches() ([]models.MatchResult, error) {
+    matches, err := s.repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+    sort.SliceStable(matches, func(i, j int) bool {
+        a, b := matches[i], matches[j]
+        if a.Week != b.Week {
+            return a.Week < b.Week
+        }
+        if !a.Kickoff.Equal(b.Kickoff) {
+            return a.Kickoff.Before(b.Kickoff)
+        }
+        return a.ID < b.ID
+    })
+    return matches, nil
+}
+
+// timeline rebuilds the table after every matchday and records where
+// teamID stood in it.
+func (s *StatsService) timeline(teamID int, matches []models.MatchResult) ([]models.StatsWeek, error) {
+    if len(matches) == 0 {
+        return nil, nil
+    }
+    teams, err := s.repo.ListTeams()
+    if err != nil {
+        return nil, err
+    }
+    adjs, err := s.repo.ListAdjustments()
+    if err != nil {
+        return nil, err
+    }
+    cfg, err := s.repo.GetSplit()
+    if err != nil {
+        return nil, err
+    }
+
+    u := NewTableUpdater()
+    lastWeek := matches[len(matches)-1].Week
+    out := make([]models.StatsWeek, 0, lastWeek)
+    next := 0
+    for week := 1; week <= lastWeek; week++ {
+        for next < len(matches) && matches[next].Week <= week {
+            next++
+        }
+        table, err := tableAfter(u, teams, matches[:next], adjs, cfg, week)
+        if err != nil {
+            return nil, err
+        }
+        // A split table comes ordered group by group already.
+        if len(table) > 0 && table[0].Group == "" {
+            table.Sort()
+        }
+        for i, row := range table {
+            if row.TeamID != teamID {
+                continue
+            }
+            sw := models.StatsWeek{Week: week, Played: row.Played, Points: row.Points, Position: i + 1}
+            if row.Played > 0 {
+                sw.PPG = float64(3*row.Won+row.Drawn) / float64(row.Played)
+            }
+            out = append(out, sw)
+        }
+    }
+    return out, nil
+}
+
+// teamStats computes teamID's form over its last formGames games, its
+// streaks and its home and away records from matches in playing order.
+func teamStats(teamID int, name string, matches []models.MatchResult, formGames int) models.TeamStats {
+    st := models.TeamStats{TeamID: teamID, TeamName: name}
+    extend := func(s *models.Streak, ok bool) {
+        if !ok {
+            s.Current = 0
+            return
+        }
+        s.Current++
+        if s.Current > s.Longest {
+            s.Longest = s.Current
+        }
+    }
+
+    var form []byte
+    var formPoints []int
+    for _, m := range matches {
+        var gf, ga int
+        var rec *models.Record
+        switch teamID {
+        case m.HomeTeamID:
+            gf, ga, rec = m.HomeGoals, m.AwayGoals, &st.Home
+        case m.AwayTeamID:
+            gf, ga, rec = m.AwayGoals, m.HomeGoals, &st.Away
+        default:
+            continue
+        }
+        rec.Played++
+        rec.GoalsFor += gf
+        rec.GoalsAgainst += ga
+        points := 0
+        switch {
+        case gf > ga:
+            rec.Won++
+            points = 3
+            form = append(form, 'W')
+        case gf == ga:
+            rec.Drawn++
+            points = 1
+            form = append(form, 'D')
+        default:
+            rec.Lost++
+            form = append(form, 'L')
+        }
+        rec.Points += points
+        formPoints = append(formPoints, points)
+        if ga == 0 {
+            rec.CleanSheets++
+        }
+        if gf == 0 {
+            st.FailedToScore++
+        }
+
+        extend(&st.Streaks.Win, gf > ga)
+        extend(&st.Streaks.Unbeaten, gf >= ga)
+        extend(&st.Streaks.Winless, gf <= ga)
+        extend(&st.Streaks.Scoreless, gf == 0)
+        extend(&st.Streaks.CleanSheet, ga == 0)
+    }
+
+    if formGames < len(form) {
+        form = form[len(form)-formGames:]
+        formPoints = formPoints[len(formPoints)-formGames:]
+    }
+    st.Form = string(form)
+    for _, p := range formPoints {
+        st.FormPoints += p
+    }
+    st.CleanSheets = st.Home.CleanSheets + st.Away.CleanSheets
+    if played := st.Home.Played + st.Away.Played; played > 0 {
+        st.PPG = float64(st.Home.Points+st.Away.Points) / float64(played)
+    }
+    return st
+}
+
+// biggerWin reports whether a was won by a wider margin than b, or by the
+// same margin with more goals.
+func biggerWin(a, b models.MatchResult) bool {
+    ma, mb := abs(a.HomeGoals-a.AwayGoals), abs(b.HomeGoals-b.AwayGoals)
+    if ma != mb {
+        return ma > mb
+    }
+    return a.HomeGoals+a.AwayGoals > b.HomeGoals+b.AwayGoals
+}
+
+func abs(n int) int {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
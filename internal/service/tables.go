@@ -0,0 +1,199 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/split"
+)
+
+// ErrInvalidTableQuery is wrapped by every invalid TableQuery.
+var ErrInvalidTableQuery = errors.New("invalid table query")
+
+// Venues a TableQuery can restrict a table to.
+const (
+    VenueHome = "home"
+    VenueAway = "away"
+)
+
+// TableQuery restricts a table to some of the season's results. The zero
+// value covers every result.
+type TableQuery struct {
+    Venue    string // VenueHome or VenueAway counts only those games of each team
+    FromWeek int    // first matchday counted; 0 for the first
+    ToWeek   int    // last matchday counted; 0 for the latest
+    Teams    []int  // when set, only the games between these teams, and only their rows
+}
+
+// TableService builds the current season's table as it stood after a
+// matchday, and tables over part of its results.
+type TableService struct {
+    repo interfaces.Repository
+}
+
+// NewTableService returns a TableService backed by repo.
+func NewTableService(repo interfaces.Repository) *TableService {
+    return &TableService{repo: repo}
+}
+
+// After returns the table as it stood once matchday week had been played,
+// with the adjustments then in effect and the split if it had happened.
+// Weeks past the latest played give the current table.
+func (s *TableService) After(week int) (models.LeagueTable, error) {
+    if week < 0 {
+        return nil, fmt.Errorf("%w: week must be ≥ 0", ErrInvalidTableQuery)
+    }
+    teams, err := s.repo.ListTeams()
+    if err != nil {
+        return nil, err
+    }
+    matches, err := s.repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+    adjs, err := s.repo.ListAdjustments()
+    if err != nil {
+        return nil, err
+    }
+    cfg, err := s.repo.GetSplit()
+    if err != nil {
+        return nil, err
+    }
+    fixtures, err := s.repo.ListFixtures()
+    if err != nil {
+        return nil, err
+    }
+
+    var played []models.MatchResult
+    lastWeek := 0
+    for _, m := range matches {
+        if m.Week > week {
+            continue
+        }
+        played = append(played, m)
+        if m.Week > lastWeek {
+            lastWeek = m.Week
+        }
+    }
+    table, err := tableAfter(NewTableUpdater(), teams, played, adjs, cfg, lastWeek)
+    if err != nil {
+        return nil, err
+    }
+    CountByes(table, fixtures, lastWeek)
+    CountPostponed(table, fixtures, lastWeek)
+    return table, nil
+}
+
+// Filtered returns a table of the results q picks, in team order. Only
+// results count: adjustments and the split's halving are left out.
+func (s *TableService) Filtered(q TableQuery) (models.LeagueTable, error) {
+    switch {
+    case q.Venue != "" && q.Venue != VenueHome && q.Venue != VenueAway:
+        return nil, fmt.Errorf("%w: venue must be %q or %q", ErrInvalidTableQuery, VenueHome, VenueAway)
+    case q.FromWeek < 0 || q.ToWeek < 0:
+        return nil, fmt.Errorf("%w: weeks must be ≥ 0", ErrInvalidTableQuery)
+    case q.ToWeek != 0 && q.FromWeek > q.ToWeek:
+        return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidTableQuery)
+    }
+    teams, err := s.repo.ListTeams()
+    if err != nil {
+        return nil, err
+    }
+    if len(q.Teams) > 0 {
+        if teams, err = pickTeams(teams, q.Teams); err != nil {
+            return nil, err
+        }
+    }
+    matches, err := s.repo.GetAllMatches()
+    if err != nil {
+        return nil, err
+    }
+
+    in := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        in[t.ID] = true
+    }
+    u := NewTableUpdater()
+    table, err := BuildTable(u, teams, nil)
+    if err != nil {
+        return nil, err
+    }
+    for _, m := range matches {
+        if m.Week < q.FromWeek || (q.ToWeek != 0 && m.Week > q.ToWeek) || !in[m.HomeTeamID] || !in[m.AwayTeamID] {
+            continue
+        }
+        if q.Venue == "" {
+            if table, err = u.Update(table, m); err != nil {
+                return nil, err
+            }
+            continue
+        }
+        // Play the match on a table of its own two sides and keep the
+        // standing of the side at the venue asked for.
+        sides, err := u.Update(models.LeagueTable{{TeamID: m.HomeTeamID}, {TeamID: m.AwayTeamID}}, m)
+        if err != nil {
+            return nil, err
+        }
+        side := sides[0]
+        if q.Venue == VenueAway {
+            side = sides[1]
+        }
+        for i := range table {
+            if table[i].TeamID == side.TeamID {
+                addStanding(&table[i], side)
+            }
+        }
+    }
+    return table, nil
+}
+
+// pickTeams returns the teams of ids, in team order, for a head-to-head
+// table.
+func pickTeams(teams []models.Team, ids []int) ([]models.Team, error) {
+    known := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        known[t.ID] = true
+    }
+    want := make(map[int]bool, len(ids))
+    for _, id := range ids {
+        if !known[id] {
+            return nil, fmt.Errorf("%w: unknown team %d", ErrInvalidTableQuery, id)
+        }
+        want[id] = true
+    }
+    if len(want) < 2 {
+        return nil, fmt.Errorf("%w: a head-to-head table needs at least two teams", ErrInvalidTableQuery)
+    }
+    var out []models.Team
+    for _, t := range teams {
+        if want[t.ID] {
+            out = append(out, t)
+        }
+    }
+    return out, nil
+}
+
+// addStanding adds the games of s to row.
+func addStanding(row *models.TeamStanding, s models.TeamStanding) {
+    row.Played += s.Played
+    row.Won += s.Won
+    row.Drawn += s.Drawn
+    row.Lost += s.Lost
+    row.GoalsFor += s.GoalsFor
+    row.GoalsAgainst += s.GoalsAgainst
+    row.GoalDiff = row.GoalsFor - row.GoalsAgainst
+    row.Points += s.Points
+}
+
+// tableAfter builds the table as it stood once matchday week had been
+// played from the results up to it: adjustments count from their week and
+// the split only once its group fixtures were under way.
+func tableAfter(u interfaces.TableUpdater, teams []models.Team, played []models.MatchResult, adjs []models.Adjustment, cfg models.SplitConfig, week int) (models.LeagueTable, error) {
+    effective, _ := EffectiveAdjustments(adjs, week)
+    if week <= cfg.AfterWeek {
+        cfg = models.SplitConfig{}
+    }
+    return split.Table(u, teams, played, effective, cfg)
+}
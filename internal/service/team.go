@@ -0,0 +1,143 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "net/url"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Limits applied by ValidateTeam.
+const (
+    MinStrength     = 1
+    MaxStrength     = 100
+    MaxShortNameLen = 5
+)
+
+var (
+    // ErrInvalidTeam is wrapped by every validation failure of TeamService.
+    ErrInvalidTeam = errors.New("invalid team")
+    // ErrSeasonStarted is returned when the team list is changed after
+    // the first result of the season has been recorded.
+    ErrSeasonStarted = errors.New("season already started")
+)
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TeamService manages the clubs of the league. Renames and strength or
+// cosmetic changes are allowed at any time; adding or removing a team
+// rebuilds the fixture list, so it is only allowed before the season has
+// started.
+type TeamService struct {
+    repo interfaces.Repository
+}
+
+// NewTeamService returns a TeamService backed by repo.
+func NewTeamService(repo interfaces.Repository) *TeamService {
+    return &TeamService{repo: repo}
+}
+
+// List returns every team ordered by id.
+func (s *TeamService) List() ([]models.Team, error) {
+    return s.repo.ListTeams()
+}
+
+// Get returns one team.
+func (s *TeamService) Get(id int) (models.Team, error) {
+    return s.repo.GetTeam(id)
+}
+
+// Create adds a team to a league that has not started yet.
+func (s *TeamService) Create(t models.Team) (models.Team, error) {
+    t = NormalizeTeam(t)
+    if err := ValidateTeam(t); err != nil {
+        return t, err
+    }
+    var created models.Team
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := checkNotStarted(tx); err != nil {
+            return err
+        }
+        var err error
+        if created, err = tx.SaveTeam(t); err != nil {
+            return err
+        }
+        return reschedule(tx)
+    })
+    return created, err
+}
+
+// Update overwrites the team with t.ID.
+func (s *TeamService) Update(t models.Team) (models.Team, error) {
+    t = NormalizeTeam(t)
+    if err := ValidateTeam(t); err != nil {
+        return t, err
+    }
+    err := withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        return tx.UpdateTeam(t)
+    })
+    return t, err
+}
+
+// Delete removes a team from a league that has not started yet.
+func (s *TeamService) Delete(id int) error {
+    return withSeasonLock(s.repo, func(tx interfaces.Repository) error {
+        if err := checkNotStarted(tx); err != nil {
+            return err
+        }
+        if err := tx.DeleteTeam(id); err != nil {
+            return err
+        }
+        return reschedule(tx)
+    })
+}
+
+// ValidateTeam rejects teams the rest of the application cannot display
+// or simulate.
+func ValidateTeam(t models.Team) error {
+    switch {
+    case t.Name == "":
+        return fmt.Errorf("%w: name is required", ErrInvalidTeam)
+    case utf8.RuneCountInString(t.ShortName) > MaxShortNameLen:
+        return fmt.Errorf("%w: short_name cannot exceed %d characters", ErrInvalidTeam, MaxShortNameLen)
+    case t.Strength < MinStrength || t.Strength > MaxStrength:
+        return fmt.Errorf("%w: strength must be between %d and %d", ErrInvalidTeam, MinStrength, MaxStrength)
+    case t.Rating < 0:
+        return fmt.Errorf("%w: rating cannot be negative", ErrInvalidTeam)
+    case t.Color != "" && !hexColor.MatchString(t.Color):
+        return fmt.Errorf("%w: color must look like #rrggbb", ErrInvalidTeam)
+    }
+    if t.CrestURL != "" {
+        u, err := url.Parse(t.CrestURL)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return fmt.Errorf("%w: crest_url must be an http(s) URL", ErrInvalidTeam)
+        }
+    }
+    return nil
+}
+
+// NormalizeTeam trims whitespace and upper-cases the short name.
+func NormalizeTeam(t models.Team) models.Team {
+    t.Name = strings.TrimSpace(t.Name)
+    t.ShortName = strings.ToUpper(strings.TrimSpace(t.ShortName))
+    t.Color = strings.TrimSpace(t.Color)
+    t.CrestURL = strings.TrimSpace(t.CrestURL)
+    return t
+}
+
+// checkNotStarted fails once the current season has any result.
+func checkNotStarted(repo interfaces.Repository) error {
+    played, err := repo.GetAllMatches()
+    if err != nil {
+        return err
+    }
+    if len(played) > 0 {
+        return fmt.Errorf("%w: %d results recorded", ErrSeasonStarted, len(played))
+    }
+    return nil
+}
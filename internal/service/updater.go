@@ -5,6 +5,7 @@ import (
 
     "github.com/musta/insider-league/internal/interfaces"
     "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
 )
 
 // TableUpdater implements interfaces.TableUpdater
@@ -57,3 +58,73 @@ func (u *TableUpdater) Update(current models.LeagueTable, result models.MatchRes
 
     return current, nil
 }
+
+// Adjust applies a points adjustment to the team's standing.
+func (u *TableUpdater) Adjust(current models.LeagueTable, adj models.Adjustment) (models.LeagueTable, error) {
+    if adj.Kind == models.AdjustmentWalkover {
+        return current, nil
+    }
+    for i := range current {
+        if current[i].TeamID == adj.TeamID {
+            current[i].Points += adj.Points
+            current[i].Adjustment += adj.Points
+            return current, nil
+        }
+    }
+    return nil, fmt.Errorf("team not found in table: %d", adj.TeamID)
+}
+
+// BuildTable starts every team on zero and applies matches in order.
+func BuildTable(u interfaces.TableUpdater, teams []models.Team, matches []models.MatchResult) (models.LeagueTable, error) {
+    table := make(models.LeagueTable, len(teams))
+    for i, t := range teams {
+        table[i] = models.TeamStanding{
+            TeamID:   t.ID,
+            TeamName: t.Name,
+        }
+    }
+    for _, m := range matches {
+        var err error
+        table, err = u.Update(table, m)
+        if err != nil {
+            return nil, fmt.Errorf("updating table: %w", err)
+        }
+    }
+    return table, nil
+}
+
+// CountByes sets each standing's Byes to the number of matchdays up to and
+// including throughWeek in which the team has no fixture.
+func CountByes(table models.LeagueTable, fixtures []interfaces.Matchup, throughWeek int) {
+    teams := make([]models.Team, len(table))
+    for i, row := range table {
+        teams[i] = models.Team{ID: row.TeamID}
+    }
+    counts := make(map[int]int)
+    for week, ids := range schedule.Byes(fixtures, teams) {
+        if week > throughWeek {
+            continue
+        }
+        for _, id := range ids {
+            counts[id]++
+        }
+    }
+    for i := range table {
+        table[i].Byes = counts[table[i].TeamID]
+    }
+}
+
+// CountPostponed sets each standing's Postponed to the number of fixtures
+// first scheduled up to throughWeek that have been moved past it.
+func CountPostponed(table models.LeagueTable, fixtures []interfaces.Matchup, throughWeek int) {
+    counts := make(map[int]int)
+    for _, f := range fixtures {
+        if f.OriginalWeek != 0 && f.OriginalWeek <= throughWeek && f.Week > throughWeek {
+            counts[f.HomeTeamID]++
+            counts[f.AwayTeamID]++
+        }
+    }
+    for i := range table {
+        table[i].Postponed = counts[table[i].TeamID]
+    }
+}
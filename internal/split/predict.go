@@ -0,0 +1,116 @@
+package split
+
+import (
+    "fmt"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// Odds is one team's chance of winning the title and of landing in each
+// group at the split.
+type Odds struct {
+    TeamID int       `json:"team_id"`
+    Title  float64   `json:"title"`
+    Groups []float64 `json:"groups"` // Groups[i]: plays the split in cfg.Groups[i]
+}
+
+// Predict plays the rest of a season that has not split yet sims times:
+// the remaining regular fixtures, the split of the table and the group
+// fixtures, every match tabulated with u and generated with a generator
+// fresh from newGen for each run. table is the current table; pending are
+// the points adjustments that take effect later in the season.
+func Predict(cfg models.SplitConfig, teams []models.Team, table models.LeagueTable, remaining []interfaces.Matchup,
+    pending []models.Adjustment, newGen interfaces.GeneratorFactory, u interfaces.TableUpdater, sims int) ([]Odds, error) {
+    if sims < 1 {
+        return nil, fmt.Errorf("%w: sims must be positive", ErrInvalidSplit)
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+    regular := Regular(cfg, remaining)
+    lastWeek := cfg.AfterWeek
+    for _, f := range regular {
+        if f.Week > lastWeek {
+            lastWeek = f.Week
+        }
+    }
+    adjust := func(sim models.LeagueTable, later bool) (models.LeagueTable, error) {
+        var err error
+        for _, a := range pending {
+            if (a.Week > cfg.AfterWeek) == later {
+                if sim, err = u.Adjust(sim, a); err != nil {
+                    return nil, err
+                }
+            }
+        }
+        return sim, nil
+    }
+    play := func(gen interfaces.MatchGenerator, sim models.LeagueTable, fixtures []interfaces.Matchup) (models.LeagueTable, error) {
+        for _, f := range fixtures {
+            m, err := gen.Generate(byID[f.HomeTeamID], byID[f.AwayTeamID])
+            if err != nil {
+                return nil, err
+            }
+            if sim, err = u.Update(sim, m); err != nil {
+                return nil, err
+            }
+        }
+        return sim, nil
+    }
+
+    titles := make(map[int]int, len(teams))
+    landed := make(map[int][]int, len(teams))
+    for s := 0; s < sims; s++ {
+        gen, err := newGen()
+        if err != nil {
+            return nil, err
+        }
+        sim := make(models.LeagueTable, len(table))
+        copy(sim, table)
+        sim, err = play(gen, sim, regular)
+        if err != nil {
+            return nil, err
+        }
+        if sim, err = adjust(sim, false); err != nil {
+            return nil, err
+        }
+        assigned := Assign(cfg, sim)
+        fixtures, err := Fixtures(assigned, lastWeek+1)
+        if err != nil {
+            return nil, err
+        }
+        Adjust(sim, assigned)
+        if sim, err = play(gen, sim, fixtures); err != nil {
+            return nil, err
+        }
+        if sim, err = adjust(sim, true); err != nil {
+            return nil, err
+        }
+        Order(sim, assigned)
+        titles[sim[0].TeamID]++
+        for gi, g := range assigned.Groups {
+            for _, id := range g.TeamIDs {
+                if landed[id] == nil {
+                    landed[id] = make([]int, len(cfg.Groups))
+                }
+                landed[id][gi]++
+            }
+        }
+    }
+
+    odds := make([]Odds, 0, len(teams))
+    for _, t := range teams {
+        o := Odds{
+            TeamID: t.ID,
+            Title:  float64(titles[t.ID]) / float64(sims),
+            Groups: make([]float64, len(cfg.Groups)),
+        }
+        for i, n := range landed[t.ID] {
+            o.Groups[i] = float64(n) / float64(sims)
+        }
+        odds = append(odds, o)
+    }
+    return odds, nil
+}
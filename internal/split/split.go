@@ -0,0 +1,212 @@
+// Package split runs split seasons: a regular phase, then the table cut
+// into groups that play extra fixtures among themselves.
+package split
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
+)
+
+// ErrInvalidSplit is wrapped by every invalid split configuration.
+var ErrInvalidSplit = errors.New("invalid split")
+
+// Validate checks cfg for a league of teams whose regular phase can run
+// to lastWeek at most.
+func Validate(cfg models.SplitConfig, teams, lastWeek int) error {
+    switch {
+    case len(cfg.Groups) < 2:
+        return fmt.Errorf("%w: a split needs at least 2 groups", ErrInvalidSplit)
+    case cfg.Legs != 1 && cfg.Legs != 2:
+        return fmt.Errorf("%w: legs must be 1 or 2", ErrInvalidSplit)
+    case cfg.AfterWeek < 1:
+        return fmt.Errorf("%w: after_week must be ≥ 1", ErrInvalidSplit)
+    case cfg.AfterWeek > lastWeek:
+        return fmt.Errorf("%w: the schedule ends in week %d, before after_week %d", ErrInvalidSplit, lastWeek, cfg.AfterWeek)
+    }
+    total := 0
+    names := make(map[string]bool, len(cfg.Groups))
+    for i, g := range cfg.Groups {
+        if g.Name == "" {
+            return fmt.Errorf("%w: group %d needs a name", ErrInvalidSplit, i+1)
+        }
+        if names[g.Name] {
+            return fmt.Errorf("%w: two groups named %q", ErrInvalidSplit, g.Name)
+        }
+        names[g.Name] = true
+        if g.Size < 2 {
+            return fmt.Errorf("%w: group %s needs at least 2 teams", ErrInvalidSplit, g.Name)
+        }
+        total += g.Size
+    }
+    if total != teams {
+        return fmt.Errorf("%w: groups hold %d teams, the league has %d", ErrInvalidSplit, total, teams)
+    }
+    return nil
+}
+
+// Fits reports whether cfg's group sizes still add up to teams.
+func Fits(cfg models.SplitConfig, teams int) bool {
+    total := 0
+    for _, g := range cfg.Groups {
+        total += g.Size
+    }
+    return total == teams
+}
+
+// Regular returns the fixtures of the regular phase.
+func Regular(cfg models.SplitConfig, fixtures []interfaces.Matchup) []interfaces.Matchup {
+    if !cfg.Enabled() {
+        return fixtures
+    }
+    out := make([]interfaces.Matchup, 0, len(fixtures))
+    for _, f := range fixtures {
+        if f.Week <= cfg.AfterWeek {
+            out = append(out, f)
+        }
+    }
+    return out
+}
+
+// Assign cuts the regular-phase table into cfg's groups, best team first,
+// and returns cfg with every group's TeamIDs filled in.
+func Assign(cfg models.SplitConfig, table models.LeagueTable) models.SplitConfig {
+    ranked := make(models.LeagueTable, len(table))
+    copy(ranked, table)
+    ranked.Sort()
+
+    out := cfg
+    out.Groups = make([]models.SplitGroup, len(cfg.Groups))
+    next := 0
+    for i, g := range cfg.Groups {
+        g.TeamIDs = nil
+        for _, row := range ranked[next : next+g.Size] {
+            g.TeamIDs = append(g.TeamIDs, row.TeamID)
+        }
+        next += g.Size
+        out.Groups[i] = g
+    }
+    return out
+}
+
+// Fixtures schedules a round-robin with cfg.Legs legs inside every group
+// of an assigned cfg. The groups play side by side from firstWeek on.
+func Fixtures(cfg models.SplitConfig, firstWeek int) ([]interfaces.Matchup, error) {
+    var out []interfaces.Matchup
+    for _, g := range cfg.Groups {
+        members := make([]models.Team, len(g.TeamIDs))
+        for i, id := range g.TeamIDs {
+            members[i] = models.Team{ID: id}
+        }
+        fixtures, err := schedule.RoundRobin{Legs: cfg.Legs}.Generate(members)
+        if err != nil {
+            return nil, fmt.Errorf("group %s: %w", g.Name, err)
+        }
+        for _, f := range fixtures {
+            f.Week += firstWeek - 1
+            out = append(out, f)
+        }
+    }
+    sort.SliceStable(out, func(i, j int) bool { return out[i].Week < out[j].Week })
+    return out, nil
+}
+
+// Table builds the standings of a season from its results and points
+// adjustments. Once cfg has been assigned, adjustments up to AfterWeek
+// count before the points are adjusted at the split, later ones after,
+// and the rows are ordered group by group; otherwise the table is left in
+// team order.
+func Table(u interfaces.TableUpdater, teams []models.Team, matches []models.MatchResult, adjs []models.Adjustment, cfg models.SplitConfig) (models.LeagueTable, error) {
+    table := make(models.LeagueTable, len(teams))
+    for i, t := range teams {
+        table[i] = models.TeamStanding{TeamID: t.ID, TeamName: t.Name}
+    }
+    after := func(week int) bool { return cfg.Done() && week > cfg.AfterWeek }
+    var err error
+    for _, m := range matches {
+        if !after(m.Week) {
+            if table, err = u.Update(table, m); err != nil {
+                return nil, fmt.Errorf("updating table: %w", err)
+            }
+        }
+    }
+    for _, a := range adjs {
+        if !after(a.Week) {
+            if table, err = u.Adjust(table, a); err != nil {
+                return nil, fmt.Errorf("adjusting table: %w", err)
+            }
+        }
+    }
+    if !cfg.Done() {
+        return table, nil
+    }
+    Adjust(table, cfg)
+    for _, m := range matches {
+        if after(m.Week) {
+            if table, err = u.Update(table, m); err != nil {
+                return nil, fmt.Errorf("updating table: %w", err)
+            }
+        }
+    }
+    for _, a := range adjs {
+        if after(a.Week) {
+            if table, err = u.Adjust(table, a); err != nil {
+                return nil, fmt.Errorf("adjusting table: %w", err)
+            }
+        }
+    }
+    Order(table, cfg)
+    return table, nil
+}
+
+// Adjust applies cfg's points adjustment to the table at the split.
+func Adjust(table models.LeagueTable, cfg models.SplitConfig) {
+    if !cfg.HalvePoints {
+        return
+    }
+    for i := range table {
+        table[i].Points = (table[i].Points + 1) / 2
+    }
+}
+
+// Order labels every row with its group and sorts the table group by
+// group, each group by points, goal difference and goals scored.
+func Order(table models.LeagueTable, cfg models.SplitConfig) {
+    group := make(map[int]int, len(table))
+    for gi, g := range cfg.Groups {
+        for _, id := range g.TeamIDs {
+            group[id] = gi
+        }
+    }
+    for i := range table {
+        table[i].Group = cfg.Groups[group[table[i].TeamID]].Name
+    }
+    table.Sort()
+    sort.SliceStable(table, func(i, j int) bool { return group[table[i].TeamID] < group[table[j].TeamID] })
+}
+
+// TopGroup narrows a split season's table and remaining fixtures to the
+// top group, the only teams still able to win the title.
+func TopGroup(cfg models.SplitConfig, table models.LeagueTable, remaining []interfaces.Matchup) (models.LeagueTable, []interfaces.Matchup) {
+    in := make(map[int]bool, cfg.Groups[0].Size)
+    for _, id := range cfg.Groups[0].TeamIDs {
+        in[id] = true
+    }
+    var rows models.LeagueTable
+    for _, row := range table {
+        if in[row.TeamID] {
+            rows = append(rows, row)
+        }
+    }
+    var fixtures []interfaces.Matchup
+    for _, f := range remaining {
+        if in[f.HomeTeamID] && in[f.AwayTeamID] {
+            fixtures = append(fixtures, f)
+        }
+    }
+    return rows, fixtures
+}
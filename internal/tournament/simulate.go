@@ -0,0 +1,197 @@
+package tournament
+
+import (
+    "fmt"
+    "math/rand"
+
+    "github.com/musta/insider-league/internal/cup"
+    "github.com/musta/insider-league/internal/interfaces"
+    "github.com/musta/insider-league/internal/models"
+    "github.com/musta/insider-league/internal/schedule"
+)
+
+// GroupResult is a group played to the end, its table sorted.
+type GroupResult struct {
+    Name    string               `json:"name"`
+    Matches []models.MatchResult `json:"matches"`
+    Table   models.LeagueTable   `json:"table"`
+}
+
+// Result is a tournament played to the end.
+type Result struct {
+    Groups   []GroupResult `json:"groups"`
+    Bracket  cup.Bracket   `json:"bracket"`
+    Knockout cup.Result    `json:"knockout"`
+    WinnerID int           `json:"winner_id"`
+}
+
+// Simulate plays every group as a round-robin, tabulated with u, then the
+// knockout between the qualifiers. Every match is generated with gen.
+func Simulate(groups []Group, teams []models.Team, gen interfaces.MatchGenerator, u interfaces.TableUpdater, cfg Config, rng *rand.Rand) (Result, error) {
+    if err := cfg.Validate(len(teams)); err != nil {
+        return Result{}, err
+    }
+    if err := ValidateGroups(groups, teams, cfg); err != nil {
+        return Result{}, err
+    }
+    byID := make(map[int]models.Team, len(teams))
+    for _, t := range teams {
+        byID[t.ID] = t
+    }
+
+    var res Result
+    groupOf := make(map[int]string, len(teams))
+    for _, g := range groups {
+        gr, err := playGroup(g, byID, gen, u, cfg.GroupLegs)
+        if err != nil {
+            return res, err
+        }
+        for _, id := range g.TeamIDs {
+            groupOf[id] = g.Name
+        }
+        res.Groups = append(res.Groups, gr)
+    }
+
+    res.Bracket = seedKnockout(res.Groups, cfg.Qualifiers, groupOf)
+    ko, err := cup.Simulate(res.Bracket, teams, gen, cfg.Knockout, rng)
+    if err != nil {
+        return res, err
+    }
+    res.Knockout, res.WinnerID = ko, ko.WinnerID
+    return res, nil
+}
+
+// playGroup plays a round-robin between the group's teams.
+func playGroup(g Group, byID map[int]models.Team, gen interfaces.MatchGenerator, u interfaces.TableUpdater, legs int) (GroupResult, error) {
+    gr := GroupResult{Name: g.Name}
+    members := make([]models.Team, len(g.TeamIDs))
+    gr.Table = make(models.LeagueTable, len(g.TeamIDs))
+    for i, id := range g.TeamIDs {
+        members[i] = byID[id]
+        gr.Table[i] = models.TeamStanding{TeamID: id, TeamName: byID[id].Name}
+    }
+    fixtures, err := schedule.RoundRobin{Legs: legs}.Generate(members)
+    if err != nil {
+        return gr, err
+    }
+    for _, f := range fixtures {
+        m, err := gen.Generate(byID[f.HomeTeamID], byID[f.AwayTeamID])
+        if err != nil {
+            return gr, fmt.Errorf("group %s, %d v %d: %w", g.Name, f.HomeTeamID, f.AwayTeamID, err)
+        }
+        m.Week = f.Week
+        if gr.Table, err = u.Update(gr.Table, m); err != nil {
+            return gr, err
+        }
+        gr.Matches = append(gr.Matches, m)
+    }
+    gr.Table.Sort()
+    return gr, nil
+}
+
+// seedKnockout ranks the qualifiers (group winners first, each place
+// ordered by points, goal difference and goals) and seeds them into a
+// bracket. First-round ties between teams of the same group are broken
+// up by swapping opponents with another tie where possible.
+func seedKnockout(groups []GroupResult, qualifiers int, groupOf map[int]string) cup.Bracket {
+    var ids []int
+    for place := 0; place < qualifiers; place++ {
+        var rows models.LeagueTable
+        for _, g := range groups {
+            rows = append(rows, g.Table[place])
+        }
+        rows.Sort()
+        for _, row := range rows {
+            ids = append(ids, row.TeamID)
+        }
+    }
+
+    b := cup.Seeded(ids)
+    s := b.Slots
+    clash := func(x, y int) bool { return x != 0 && y != 0 && groupOf[x] == groupOf[y] }
+    for i := 0; i < len(s); i += 2 {
+        if !clash(s[i], s[i+1]) {
+            continue
+        }
+        for j := 0; j < len(s); j += 2 {
+            // Byes stay with the seeds they were given to.
+            if j != i && s[j+1] != 0 && !clash(s[i], s[j+1]) && !clash(s[j], s[i+1]) {
+                s[i+1], s[j+1] = s[j+1], s[i+1]
+                break
+            }
+        }
+    }
+    return b
+}
+
+// Odds is one team's chance of getting through each stage.
+type Odds struct {
+    TeamID  int       `json:"team_id"`
+    Advance float64   `json:"advance"` // leaves the group
+    Reach   []float64 `json:"reach"`   // Reach[i]: plays in knockout round i
+    Win     float64   `json:"win"`
+}
+
+// Predict simulates the tournament sims times, each run with a generator
+// fresh from newGen. With nil groups every run makes a fresh pot draw;
+// otherwise the given groups are kept.
+func Predict(groups []Group, teams []models.Team, newGen interfaces.GeneratorFactory, u interfaces.TableUpdater, cfg Config, sims int, rng *rand.Rand) ([]Odds, error) {
+    if sims < 1 {
+        return nil, fmt.Errorf("%w: sims must be positive", ErrInvalidTournament)
+    }
+    if err := cfg.Validate(len(teams)); err != nil {
+        return nil, err
+    }
+    rounds := len(cup.RoundNames(cfg.Groups * cfg.Qualifiers))
+    advanced := make(map[int]int, len(teams))
+    reached := make(map[int][]int, len(teams))
+    wins := make(map[int]int, len(teams))
+    for s := 0; s < sims; s++ {
+        draw := groups
+        if draw == nil {
+            draw = DrawGroups(teams, cfg.Groups, rng)
+        }
+        gen, err := newGen()
+        if err != nil {
+            return nil, err
+        }
+        res, err := Simulate(draw, teams, gen, u, cfg, rng)
+        if err != nil {
+            return nil, err
+        }
+        for _, id := range res.Bracket.Slots {
+            if id != 0 {
+                advanced[id]++
+            }
+        }
+        for i, round := range res.Knockout.Rounds {
+            for _, tie := range round.Ties {
+                for _, id := range []int{tie.HomeTeamID, tie.AwayTeamID} {
+                    if id == 0 {
+                        continue
+                    }
+                    if reached[id] == nil {
+                        reached[id] = make([]int, rounds)
+                    }
+                    reached[id][i]++
+                }
+            }
+        }
+        wins[res.WinnerID]++
+    }
+
+    odds := make([]Odds, 0, len(teams))
+    for _, t := range teams {
+        o := Odds{
+            TeamID:  t.ID,
+            Advance: float64(advanced[t.ID]) / float64(sims),
+            Reach:   make([]float64, rounds),
+            Win:     float64(wins[t.ID]) / float64(sims),
+        }
+        for i, n := range reached[t.ID] {
+            o.Reach[i] = float64(n) / float64(sims)
+        }
+        odds = append(odds, o)
+    }
+    return odds, nil
+}
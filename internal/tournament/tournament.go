@@ -0,0 +1,117 @@
+// Package tournament runs group-stage-plus-knockout competitions: groups
+// play a round-robin, the top teams of each group go into a seeded cup.
+package tournament
+
+import (
+    "errors"
+    "fmt"
+    "math/rand"
+    "sort"
+
+    "github.com/musta/insider-league/internal/cup"
+    "github.com/musta/insider-league/internal/models"
+)
+
+// ErrInvalidTournament is wrapped by every invalid draw or configuration.
+var ErrInvalidTournament = errors.New("invalid tournament")
+
+// Config describes the format of a tournament.
+type Config struct {
+    Groups     int        `json:"groups"`     // number of groups
+    Qualifiers int        `json:"qualifiers"` // teams per group that reach the knockout
+    GroupLegs  int        `json:"group_legs"` // 1 or 2 meetings per pair in a group
+    Knockout   cup.Config `json:"knockout"`
+}
+
+// DefaultConfig is two single round-robin groups with the top two going
+// through to single-match semi-finals.
+func DefaultConfig() Config {
+    return Config{Groups: 2, Qualifiers: 2, GroupLegs: 1, Knockout: cup.DefaultConfig()}
+}
+
+// Validate reports the first invalid field for a tournament of teams.
+func (c Config) Validate(teams int) error {
+    switch {
+    case c.Groups < 1:
+        return fmt.Errorf("%w: groups must be ≥ 1", ErrInvalidTournament)
+    case c.GroupLegs != 1 && c.GroupLegs != 2:
+        return fmt.Errorf("%w: group_legs must be 1 or 2", ErrInvalidTournament)
+    case c.Qualifiers < 1:
+        return fmt.Errorf("%w: qualifiers must be ≥ 1", ErrInvalidTournament)
+    case c.Groups*c.Qualifiers < 2:
+        return fmt.Errorf("%w: at least 2 teams must reach the knockout", ErrInvalidTournament)
+    case teams/c.Groups < 2:
+        return fmt.Errorf("%w: %d teams cannot fill %d groups of at least 2", ErrInvalidTournament, teams, c.Groups)
+    case teams/c.Groups < c.Qualifiers:
+        return fmt.Errorf("%w: groups of %d cannot send %d teams through", ErrInvalidTournament, teams/c.Groups, c.Qualifiers)
+    }
+    return c.Knockout.Validate()
+}
+
+// Group is one group of the draw.
+type Group struct {
+    Name    string `json:"name"`
+    TeamIDs []int  `json:"team_ids"`
+}
+
+// DrawGroups splits teams into groups from pots: the strongest teams form
+// the first pot, the next strongest the second, and so on, and each group
+// gets one team from each pot at random.
+func DrawGroups(teams []models.Team, groups int, rng *rand.Rand) []Group {
+    order := make([]models.Team, len(teams))
+    copy(order, teams)
+    sort.SliceStable(order, func(i, j int) bool { return order[i].Strength > order[j].Strength })
+
+    out := make([]Group, groups)
+    for g := range out {
+        out[g].Name = groupName(g)
+    }
+    for start := 0; start < len(order); start += groups {
+        end := start + groups
+        if end > len(order) {
+            end = len(order)
+        }
+        pot := order[start:end]
+        for i, g := range rng.Perm(groups)[:len(pot)] {
+            out[g].TeamIDs = append(out[g].TeamIDs, pot[i].ID)
+        }
+    }
+    return out
+}
+
+// ValidateGroups checks a hand-made draw against teams and cfg.
+func ValidateGroups(groups []Group, teams []models.Team, cfg Config) error {
+    if len(groups) != cfg.Groups {
+        return fmt.Errorf("%w: %d groups drawn, config wants %d", ErrInvalidTournament, len(groups), cfg.Groups)
+    }
+    known := make(map[int]bool, len(teams))
+    for _, t := range teams {
+        known[t.ID] = true
+    }
+    seen := make(map[int]bool, len(teams))
+    for _, g := range groups {
+        if len(g.TeamIDs) < 2 || len(g.TeamIDs) < cfg.Qualifiers {
+            return fmt.Errorf("%w: group %s is too small", ErrInvalidTournament, g.Name)
+        }
+        for _, id := range g.TeamIDs {
+            if !known[id] {
+                return fmt.Errorf("%w: unknown team %d in group %s", ErrInvalidTournament, id, g.Name)
+            }
+            if seen[id] {
+                return fmt.Errorf("%w: team %d drawn twice", ErrInvalidTournament, id)
+            }
+            seen[id] = true
+        }
+    }
+    return nil
+}
+
+// groupName returns "A", "B", ... "Z", "AA", ...
+func groupName(i int) string {
+    name := ""
+    for i >= 0 {
+        name = string(rune('A'+i%26)) + name
+        i = i/26 - 1
+    }
+    return name
+}
@@ -4,15 +4,328 @@
 CREATE TABLE teams (
   id SERIAL PRIMARY KEY,
   name TEXT NOT NULL,
-  strength INT NOT NULL
+  short_name TEXT NOT NULL DEFAULT '',
+  strength INT NOT NULL,
+  rating DOUBLE PRECISION NOT NULL DEFAULT 0,  -- Elo rating, 0 = unrated
+  color TEXT NOT NULL DEFAULT '',
+  crest_url TEXT NOT NULL DEFAULT ''
 );
 
--- Matches table
+-- Seasons; exactly one is current
+CREATE TABLE seasons (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  is_current BOOLEAN NOT NULL DEFAULT FALSE,
+  schedule TEXT NOT NULL DEFAULT 'double',  -- format of the fixture list
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX seasons_one_current ON seasons (is_current) WHERE is_current;
+
+INSERT INTO seasons (name, is_current) VALUES ('Season 1', TRUE);
+
+-- Per-season team strengths and ratings, written when a snapshot is
+-- restored or forked. A season with rows here plays only those teams; a
+-- season without plays every team at its shared strength and rating.
+CREATE TABLE season_teams (
+  season_id INT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+  team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  strength INT NOT NULL,
+  rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+  PRIMARY KEY (season_id, team_id)
+);
+
+-- Matches table; a fixture can only be played once per season
 CREATE TABLE matches (
   id SERIAL PRIMARY KEY,
+  season_id INT NOT NULL REFERENCES seasons(id),
   week INT NOT NULL,
   home_team_id INT NOT NULL REFERENCES teams(id),
   away_team_id INT NOT NULL REFERENCES teams(id),
   home_goals INT NOT NULL,
-  away_goals INT NOT NULL
+  away_goals INT NOT NULL,
+  kickoff TIMESTAMPTZ,  -- when it was played, if the season has dates
+  UNIQUE (season_id, week, home_team_id, away_team_id)
+);
+
+-- Results imported from outside the league, e.g. past seasons of a real
+-- competition. Teams are known by name and matched to the league's teams
+-- by name or short name.
+CREATE TABLE historical_matches (
+  id SERIAL PRIMARY KEY,
+  source TEXT NOT NULL,
+  competition TEXT NOT NULL DEFAULT '',
+  season TEXT NOT NULL DEFAULT '',
+  played_on DATE,
+  home_team TEXT NOT NULL,
+  away_team TEXT NOT NULL,
+  home_goals INT NOT NULL,
+  away_goals INT NOT NULL,
+  UNIQUE NULLS NOT DISTINCT (source, competition, season, played_on, home_team, away_team)
+);
+
+-- Optional statistics of a game; each pair is home, away. Imported
+-- results keep theirs apart so resetting the league leaves them alone.
+CREATE TABLE match_stats (
+  match_id INT PRIMARY KEY REFERENCES matches(id) ON DELETE CASCADE,
+  home_xg DOUBLE PRECISION NOT NULL DEFAULT 0,  -- both 0 = unknown
+  away_xg DOUBLE PRECISION NOT NULL DEFAULT 0,
+  home_shots INT NOT NULL DEFAULT 0,
+  away_shots INT NOT NULL DEFAULT 0,
+  home_shots_on_target INT NOT NULL DEFAULT 0,
+  away_shots_on_target INT NOT NULL DEFAULT 0,
+  home_possession INT NOT NULL DEFAULT 0,  -- percent, both 0 = unknown
+  away_possession INT NOT NULL DEFAULT 0,
+  home_corners INT NOT NULL DEFAULT 0,
+  away_corners INT NOT NULL DEFAULT 0,
+  home_yellow_cards INT NOT NULL DEFAULT 0,
+  away_yellow_cards INT NOT NULL DEFAULT 0,
+  home_red_cards INT NOT NULL DEFAULT 0,
+  away_red_cards INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE historical_match_stats (
+  historical_id INT PRIMARY KEY REFERENCES historical_matches(id) ON DELETE CASCADE,
+  home_xg DOUBLE PRECISION NOT NULL DEFAULT 0,  -- both 0 = unknown
+  away_xg DOUBLE PRECISION NOT NULL DEFAULT 0,
+  home_shots INT NOT NULL DEFAULT 0,
+  away_shots INT NOT NULL DEFAULT 0,
+  home_shots_on_target INT NOT NULL DEFAULT 0,
+  away_shots_on_target INT NOT NULL DEFAULT 0,
+  home_possession INT NOT NULL DEFAULT 0,  -- percent, both 0 = unknown
+  away_possession INT NOT NULL DEFAULT 0,
+  home_corners INT NOT NULL DEFAULT 0,
+  away_corners INT NOT NULL DEFAULT 0,
+  home_yellow_cards INT NOT NULL DEFAULT 0,
+  away_yellow_cards INT NOT NULL DEFAULT 0,
+  home_red_cards INT NOT NULL DEFAULT 0,
+  away_red_cards INT NOT NULL DEFAULT 0
+);
+
+-- Goals of a league result in the order they were scored; team_id is the
+-- side a goal counts for, the scorer's opponent for an own goal
+CREATE TABLE match_goals (
+  match_id INT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+  seq INT NOT NULL,
+  minute INT NOT NULL,
+  added_time INT NOT NULL DEFAULT 0,
+  team_id INT NOT NULL,
+  scorer TEXT NOT NULL DEFAULT '',
+  penalty BOOLEAN NOT NULL DEFAULT FALSE,
+  own_goal BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (match_id, seq)
+);
+
+-- Administrative changes to a season's table: points adjustments that
+-- count from a matchday on, and walkovers, whose 3-0 result is stored in
+-- matches like any other
+CREATE TABLE adjustments (
+  id SERIAL PRIMARY KEY,
+  season_id INT NOT NULL REFERENCES seasons(id),
+  kind TEXT NOT NULL CHECK (kind IN ('points', 'walkover')),
+  team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  points INT NOT NULL DEFAULT 0,
+  week INT NOT NULL DEFAULT 0,
+  home_team_id INT REFERENCES teams(id) ON DELETE CASCADE,
+  away_team_id INT REFERENCES teams(id) ON DELETE CASCADE,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Fixture list of each season, in the order it is played.
+-- Seasons without rows use a double round-robin of the current teams.
+-- original_week is set once a fixture has been postponed to a later week;
+-- kickoff only when it differs from the season_dates of its matchday.
+CREATE TABLE fixtures (
+  season_id INT NOT NULL REFERENCES seasons(id),
+  week INT NOT NULL,
+  slot INT NOT NULL,
+  home_team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  away_team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  original_week INT,
+  kickoff TIMESTAMPTZ,
+  PRIMARY KEY (season_id, week, home_team_id, away_team_id)
+);
+
+-- Calendar of a season: matchday 1 on start_date, weekend matchdays
+-- days_between days apart and midweek matchdays midweek_offset days
+-- after the one before.
+CREATE TABLE season_dates (
+  season_id INT PRIMARY KEY REFERENCES seasons(id),
+  start_date DATE NOT NULL,
+  days_between INT NOT NULL,
+  kickoff TEXT NOT NULL,
+  midweek_weeks INT[] NOT NULL DEFAULT '{}',
+  midweek_offset INT NOT NULL,
+  midweek_kickoff TEXT NOT NULL,
+  timezone TEXT NOT NULL
+);
+
+-- Split-season format of a season: after after_week the table is cut
+-- into groups (position 0 = top) that play more fixtures among themselves.
+-- team_ids stays empty until the season has split.
+CREATE TABLE season_splits (
+  season_id INT PRIMARY KEY REFERENCES seasons(id),
+  after_week INT NOT NULL,
+  legs INT NOT NULL DEFAULT 1,
+  halve_points BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE split_groups (
+  season_id INT NOT NULL REFERENCES season_splits(season_id) ON DELETE CASCADE,
+  position INT NOT NULL,
+  name TEXT NOT NULL,
+  size INT NOT NULL,
+  team_ids INT[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY (season_id, position)
+);
+
+-- League pyramid: divisions by level (1 = top) and their members
+CREATE TABLE divisions (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  level INT NOT NULL UNIQUE,
+  promotion INT NOT NULL DEFAULT 0,
+  relegation INT NOT NULL DEFAULT 0,
+  playoff INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE division_teams (
+  division_id INT NOT NULL REFERENCES divisions(id) ON DELETE CASCADE,
+  team_id INT PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE
+);
+
+-- Per-season scoring environment for the match generators
+CREATE TABLE generator_configs (
+  season_id INT PRIMARY KEY REFERENCES seasons(id),
+  avg_goals DOUBLE PRECISION NOT NULL,
+  home_advantage DOUBLE PRECISION NOT NULL,
+  dispersion DOUBLE PRECISION NOT NULL DEFAULT 0,
+  mapping TEXT NOT NULL DEFAULT 'share',
+  strength_scale DOUBLE PRECISION NOT NULL DEFAULT 75
+);
+
+-- Named copies of a season's teams, results and adjustments
+CREATE TABLE snapshots (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  season_id INT NOT NULL REFERENCES seasons(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE snapshot_teams (
+  snapshot_id INT NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+  team_id INT NOT NULL,
+  name TEXT NOT NULL,
+  short_name TEXT NOT NULL DEFAULT '',
+  strength INT NOT NULL,
+  rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+  color TEXT NOT NULL DEFAULT '',
+  crest_url TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (snapshot_id, team_id)
+);
+
+CREATE TABLE snapshot_matches (
+  id SERIAL PRIMARY KEY,
+  snapshot_id INT NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+  week INT NOT NULL,
+  home_team_id INT NOT NULL,
+  away_team_id INT NOT NULL,
+  home_goals INT NOT NULL,
+  away_goals INT NOT NULL,
+  kickoff TIMESTAMPTZ
+);
+
+-- Details of a snapshot's results, as in match_stats and match_goals
+CREATE TABLE snapshot_match_stats (
+  snapshot_match_id INT PRIMARY KEY REFERENCES snapshot_matches(id) ON DELETE CASCADE,
+  home_xg DOUBLE PRECISION NOT NULL DEFAULT 0,  -- both 0 = unknown
+  away_xg DOUBLE PRECISION NOT NULL DEFAULT 0,
+  home_shots INT NOT NULL DEFAULT 0,
+  away_shots INT NOT NULL DEFAULT 0,
+  home_shots_on_target INT NOT NULL DEFAULT 0,
+  away_shots_on_target INT NOT NULL DEFAULT 0,
+  home_possession INT NOT NULL DEFAULT 0,  -- percent, both 0 = unknown
+  away_possession INT NOT NULL DEFAULT 0,
+  home_corners INT NOT NULL DEFAULT 0,
+  away_corners INT NOT NULL DEFAULT 0,
+  home_yellow_cards INT NOT NULL DEFAULT 0,
+  away_yellow_cards INT NOT NULL DEFAULT 0,
+  home_red_cards INT NOT NULL DEFAULT 0,
+  away_red_cards INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE snapshot_match_goals (
+  snapshot_match_id INT NOT NULL REFERENCES snapshot_matches(id) ON DELETE CASCADE,
+  seq INT NOT NULL,
+  minute INT NOT NULL,
+  added_time INT NOT NULL DEFAULT 0,
+  team_id INT NOT NULL,
+  scorer TEXT NOT NULL DEFAULT '',
+  penalty BOOLEAN NOT NULL DEFAULT FALSE,
+  own_goal BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (snapshot_match_id, seq)
+);
+
+CREATE TABLE snapshot_adjustments (
+  snapshot_id INT NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  team_id INT NOT NULL,
+  points INT NOT NULL DEFAULT 0,
+  week INT NOT NULL DEFAULT 0,
+  home_team_id INT,
+  away_team_id INT,
+  reason TEXT NOT NULL
+);
+
+-- Append-only history of every change to matches. match_id is NULL for
+-- 'reset' events, which mark a wipe of all matches.
+CREATE TABLE match_events (
+  id BIGSERIAL PRIMARY KEY,
+  match_id INT,
+  season_id INT NOT NULL,
+  action TEXT NOT NULL CHECK (action IN ('insert', 'update', 'delete', 'reset')),
+  week INT,
+  home_team_id INT,
+  away_team_id INT,
+  old_home_goals INT,
+  old_away_goals INT,
+  new_home_goals INT,
+  new_away_goals INT,
+  actor TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX match_events_match ON match_events (match_id);
+CREATE INDEX match_events_season ON match_events (season_id, created_at);
+
+CREATE FUNCTION match_events_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'match_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER match_events_no_change
+  BEFORE UPDATE OR DELETE ON match_events
+  FOR EACH ROW EXECUTE FUNCTION match_events_append_only();
+
+-- Background simulations. A running job refreshes heartbeat_at while it
+-- works; one whose heartbeat stops (its server died) is queued again.
+-- attempts tells a worker's attempt apart from a later one.
+CREATE TABLE jobs (
+  id SERIAL PRIMARY KEY,
+  kind TEXT NOT NULL CHECK (kind IN ('predict', 'backtest', 'season_batch')),
+  status TEXT NOT NULL CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+  params JSONB NOT NULL,
+  progress DOUBLE PRECISION NOT NULL DEFAULT 0,
+  result JSONB,
+  error TEXT NOT NULL DEFAULT '',
+  attempts INT NOT NULL DEFAULT 0,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  heartbeat_at TIMESTAMPTZ
 );
+
+CREATE INDEX jobs_queued ON jobs (id) WHERE status = 'queued';